@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CallbackListener is the loopback HTTP server Authenticate waits on for the
+// OAuth authorization-code redirect. It's split out from OAuthFlow so the
+// bind/port-selection, security headers, and success-page rendering are a
+// single reusable piece rather than duplicated across the browser, manual,
+// and (should a future provider need it) device flows.
+type CallbackListener struct {
+	listener  net.Listener
+	srv       *http.Server
+	once      sync.Once
+	codeChan  chan string
+	stateChan chan string
+	errChan   chan error
+}
+
+// NewCallbackListener binds a loopback-only listener on 127.0.0.1:port.
+// Passing port 0 lets the OS choose a free port - callers read it back via
+// Port() before building the authorization URL.
+func NewCallbackListener(port int) (*CallbackListener, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind callback listener: %w", err)
+	}
+
+	cl := &CallbackListener{
+		listener:  listener,
+		codeChan:  make(chan string, 1),
+		stateChan: make(chan string, 1),
+		errChan:   make(chan error, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", cl.handleCallback)
+	mux.HandleFunc("/", cl.handleNotFound)
+	cl.srv = &http.Server{Handler: mux}
+
+	return cl, nil
+}
+
+// Port returns the port the listener is actually bound to.
+func (cl *CallbackListener) Port() int {
+	return cl.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Serve starts accepting connections in the background.
+func (cl *CallbackListener) Serve() {
+	go func() {
+		if err := cl.srv.Serve(cl.listener); err != nil && err != http.ErrServerClosed {
+			cl.errChan <- err
+		}
+	}()
+}
+
+// Shutdown stops the listener. Safe to call even if Serve was never called.
+func (cl *CallbackListener) Shutdown() {
+	cl.srv.Shutdown(context.Background())
+	// Shutdown only closes listeners handed to Serve; close ours directly too.
+	cl.listener.Close()
+}
+
+// Wait blocks until the redirect arrives, ctx is cancelled, or timeout
+// elapses, returning the authorization code. expectedState must match the
+// state the redirect reports, guarding against CSRF.
+func (cl *CallbackListener) Wait(ctx context.Context, expectedState string, timeout time.Duration) (string, error) {
+	select {
+	case code := <-cl.codeChan:
+		receivedState := <-cl.stateChan
+		if receivedState != expectedState {
+			return "", fmt.Errorf("state mismatch - possible CSRF attack")
+		}
+		return code, nil
+	case err := <-cl.errChan:
+		return "", err
+	case <-ctx.Done():
+		return "", fmt.Errorf("authentication timed out")
+	case <-time.After(timeout):
+		return "", fmt.Errorf("authentication timed out after %v", timeout)
+	}
+}
+
+// setCallbackSecurityHeaders applies headers common to every response this
+// listener sends: the authorization code lives only in the query string of
+// one request, so it must never be cached, sniffed into executing content,
+// or leaked onward via Referer.
+func setCallbackSecurityHeaders(w http.ResponseWriter) {
+	h := w.Header()
+	h.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Referrer-Policy", "no-referrer")
+	h.Set("Cache-Control", "no-store")
+	h.Set("Content-Type", "text/html")
+}
+
+func (cl *CallbackListener) handleCallback(w http.ResponseWriter, r *http.Request) {
+	setCallbackSecurityHeaders(w)
+
+	code := r.URL.Query().Get("code")
+	state := r.URL.Query().Get("state")
+	errorParam := r.URL.Query().Get("error")
+
+	// A retried or double-clicked redirect must not send again on the
+	// single-slot channels after the first real delivery already woke Wait.
+	cl.once.Do(func() {
+		switch {
+		case errorParam != "":
+			cl.errChan <- fmt.Errorf("OAuth error: %s", errorParam)
+		case code == "":
+			cl.errChan <- fmt.Errorf("no authorization code received")
+		default:
+			cl.stateChan <- state
+			cl.codeChan <- code
+		}
+	})
+
+	switch {
+	case errorParam != "":
+		fmt.Fprintf(w, `<html><body><h1>Authentication Failed</h1><p>Error: %s</p></body></html>`, html.EscapeString(errorParam))
+	case code == "":
+		fmt.Fprint(w, `<html><body><h1>Authentication Failed</h1><p>No authorization code received</p></body></html>`)
+	default:
+		fmt.Fprint(w, `<html><body><h1>Authentication Successful!</h1><p>You can close this window and return to your terminal.</p><script>window.setTimeout(function(){window.close()}, 2000);</script></body></html>`)
+	}
+}
+
+func (cl *CallbackListener) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	setCallbackSecurityHeaders(w)
+	w.WriteHeader(http.StatusNotFound)
+	fmt.Fprint(w, `<html><body><h1>Not Found</h1></body></html>`)
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCallbackListenerEscapesErrorParam(t *testing.T) {
+	cl, err := NewCallbackListener(0)
+	if err != nil {
+		t.Fatalf("NewCallbackListener returned error: %v", err)
+	}
+	defer cl.Shutdown()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/callback?error=%3Cscript%3Ealert(1)%3C%2Fscript%3E", nil)
+	cl.handleCallback(rec, req)
+
+	body, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+
+	if strings.Contains(string(body), "<script>") {
+		t.Errorf("expected error param to be HTML-escaped, got raw markup in body: %s", body)
+	}
+	if !strings.Contains(string(body), "&lt;script&gt;") {
+		t.Errorf("expected escaped error param in body, got: %s", body)
+	}
+}
+
+func TestCallbackListenerSingleShot(t *testing.T) {
+	cl, err := NewCallbackListener(0)
+	if err != nil {
+		t.Fatalf("NewCallbackListener returned error: %v", err)
+	}
+	defer cl.Shutdown()
+
+	req := httptest.NewRequest(http.MethodGet, "/callback?code=abc123&state=xyz", nil)
+
+	cl.handleCallback(httptest.NewRecorder(), req)
+	cl.handleCallback(httptest.NewRecorder(), req)
+
+	select {
+	case code := <-cl.codeChan:
+		if code != "abc123" {
+			t.Errorf("expected code %q, got %q", "abc123", code)
+		}
+	default:
+		t.Fatal("expected a code to be delivered on the first callback")
+	}
+
+	select {
+	case <-cl.codeChan:
+		t.Fatal("expected only one code delivery across duplicate callbacks")
+	default:
+	}
+}
+
+func TestCallbackListenerNotFound(t *testing.T) {
+	cl, err := NewCallbackListener(0)
+	if err != nil {
+		t.Fatalf("NewCallbackListener returned error: %v", err)
+	}
+	defer cl.Shutdown()
+
+	rec := httptest.NewRecorder()
+	cl.handleNotFound(rec, httptest.NewRequest(http.MethodGet, "/other", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
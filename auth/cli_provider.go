@@ -1,10 +1,18 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
+// jwtRefreshLeeway mirrors Pinniped's minIDTokenValidity check: a token is
+// refreshed once less than this much of its lifetime remains, rather than
+// waiting for it to actually expire mid-request.
+const jwtRefreshLeeway = 10 * time.Minute
+
 // CLIAuthProvider implements AuthProvider for CLI using config and envvars
 type CLIAuthProvider struct {
 	config *Config
@@ -32,19 +40,27 @@ func (p *CLIAuthProvider) AddAuth(req *http.Request) error {
 		return nil
 
 	case AuthModeJWT:
-		token, err := p.loadJWTToken()
+		token, err := p.loadJWTToken(req.Context())
 		if err != nil {
 			return fmt.Errorf("failed to get JWT token: %w", err)
 		}
 		req.Header.Set("Authorization", "Bearer "+token)
 		return nil
 
+	case AuthModeStaticPassword:
+		token, err := p.loadStaticPasswordToken()
+		if err != nil {
+			return fmt.Errorf("failed to get session token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+
 	default:
 		return fmt.Errorf("invalid auth_mode '%s'", p.config.AuthMode)
 	}
 }
 
-func (p *CLIAuthProvider) loadJWTToken() (string, error) {
+func (p *CLIAuthProvider) loadJWTToken(ctx context.Context) (string, error) {
 	t, err := LoadToken(p.config.ConfigDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to load token: %w", err)
@@ -55,20 +71,63 @@ func (p *CLIAuthProvider) loadJWTToken() (string, error) {
 		return "", fmt.Errorf("no id_token present; ensure 'openid' scope and re-authenticate")
 	}
 
-	if t.IsValid() {
+	if t.ValidFor(jwtRefreshLeeway) {
 		return t.IDToken, nil
 	}
 
 	if p.config.OAuthUseRefresh && t.RefreshToken != "" {
-		refreshResponse, err := RefreshAccessToken(p.config, t.RefreshToken)
-		if err != nil {
+		refreshResponse, err := RefreshAccessToken(ctx, p.config, t.RefreshToken)
+		if err == nil {
+			if err := SaveToken(refreshResponse, p.config.ConfigDir, p.config.OAuthProvider); err != nil {
+				return "", fmt.Errorf("failed to save refreshed token: %w", err)
+			}
+			return refreshResponse.IDToken, nil
+		}
+
+		if !errors.Is(err, ErrInvalidGrant) {
+			// Transient failure (network, server hiccup) - the caller may
+			// still have a usable token if we were only refreshing early.
+			if t.IsValid() {
+				return t.IDToken, nil
+			}
 			return "", fmt.Errorf("failed to refresh access token: %w", err)
 		}
-		err = SaveToken(refreshResponse, p.config.ConfigDir, p.config.OAuthProvider)
+
+		// The refresh token itself is dead; only now is it safe to fall back
+		// to a full interactive browser flow instead of surfacing an error.
+		if err := Authenticate(p.config); err != nil {
+			return "", fmt.Errorf("refresh token rejected and re-authentication failed: %w", err)
+		}
+		t, err = LoadToken(p.config.ConfigDir)
 		if err != nil {
-			return "", fmt.Errorf("failed to save refreshed token: %w", err)
+			return "", fmt.Errorf("failed to load token after re-authenticating: %w", err)
 		}
-		return refreshResponse.IDToken, nil
+		return t.IDToken, nil
+	}
+
+	if t.IsValid() {
+		return t.IDToken, nil
 	}
 	return "", fmt.Errorf("token expired and no refresh token available; please re-authenticate with 'tom auth login'")
 }
+
+// loadStaticPasswordToken returns a valid session token for static_password
+// mode, re-running the password-for-token exchange automatically when the
+// cached one is missing or expired. Unlike JWT mode, there's no browser step
+// to ask the operator to repeat, so it's safe to do this transparently.
+func (p *CLIAuthProvider) loadStaticPasswordToken() (string, error) {
+	t, err := LoadToken(p.config.ConfigDir)
+	if err == nil && t.AccessToken != "" && time.Now().Before(t.ExpiresAt.Add(-60*time.Second)) {
+		return t.AccessToken, nil
+	}
+
+	if err := AuthenticateStaticPassword(p.config); err != nil {
+		return "", fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	t, err = LoadToken(p.config.ConfigDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to load token after authenticating: %w", err)
+	}
+	return t.AccessToken, nil
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CommandCacheEntry is one cached device command result, stored as
+// <configDir>/cmd_cache/<device>/<sha256(command)>.json.
+type CommandCacheEntry struct {
+	Output    string    `json:"output"`
+	CachedAt  time.Time `json:"cached_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	TTL       int       `json:"ttl"`
+}
+
+func commandCacheDir(configDir, device string) string {
+	return filepath.Join(configDir, "cmd_cache", device)
+}
+
+func commandCachePath(configDir, device, command string) string {
+	sum := sha256.Sum256([]byte(command))
+	return filepath.Join(commandCacheDir(configDir, device), hex.EncodeToString(sum[:])+".json")
+}
+
+// LoadCommandCache returns the cached output for (device, command), if any
+// entry exists on disk and hasn't expired. found is false on a miss or an
+// expired entry - callers should fall back to querying the device.
+func LoadCommandCache(configDir, device, command string) (output string, found bool, err error) {
+	data, err := os.ReadFile(commandCachePath(configDir, device, command))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read command cache: %w", err)
+	}
+
+	var entry CommandCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", false, fmt.Errorf("failed to parse command cache entry: %w", err)
+	}
+
+	if time.Now().After(entry.ExpiresAt) {
+		return "", false, nil
+	}
+
+	return entry.Output, true, nil
+}
+
+// SaveCommandCache writes output for (device, command) to the local command
+// cache, valid for ttlSeconds from now.
+func SaveCommandCache(configDir, device, command, output string, ttlSeconds int) error {
+	dir := commandCacheDir(configDir, device)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create command cache directory: %w", err)
+	}
+
+	now := time.Now()
+	entry := CommandCacheEntry{
+		Output:    output,
+		CachedAt:  now,
+		ExpiresAt: now.Add(time.Duration(ttlSeconds) * time.Second),
+		TTL:       ttlSeconds,
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal command cache entry: %w", err)
+	}
+
+	if err := os.WriteFile(commandCachePath(configDir, device, command), data, 0644); err != nil {
+		return fmt.Errorf("failed to write command cache entry: %w", err)
+	}
+	return nil
+}
+
+// commandCacheFiles lists every cache entry file path under configDir's
+// cmd_cache directory, optionally scoped to a single device.
+func commandCacheFiles(configDir, device string) ([]string, error) {
+	pattern := filepath.Join(configDir, "cmd_cache", "*", "*.json")
+	if device != "" {
+		pattern = filepath.Join(commandCacheDir(configDir, device), "*.json")
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list command cache: %w", err)
+	}
+	return matches, nil
+}
+
+// CommandCacheListEntry summarizes one on-disk cache entry for 'tomclient
+// cache list --local'.
+type CommandCacheListEntry struct {
+	Device    string
+	CachedAt  time.Time
+	ExpiresAt time.Time
+	Expired   bool
+}
+
+// ListCommandCache returns every local command cache entry, optionally
+// scoped to a single device.
+func ListCommandCache(configDir, device string) ([]CommandCacheListEntry, error) {
+	paths, err := commandCacheFiles(configDir, device)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entries := make([]CommandCacheListEntry, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var cached CommandCacheEntry
+		if err := json.Unmarshal(data, &cached); err != nil {
+			continue
+		}
+
+		entries = append(entries, CommandCacheListEntry{
+			Device:    filepath.Base(filepath.Dir(path)),
+			CachedAt:  cached.CachedAt,
+			ExpiresAt: cached.ExpiresAt,
+			Expired:   now.After(cached.ExpiresAt),
+		})
+	}
+	return entries, nil
+}
+
+// ClearCommandCache removes every local command cache entry, optionally
+// scoped to a single device, and returns how many files were removed.
+func ClearCommandCache(configDir, device string) (int, error) {
+	paths, err := commandCacheFiles(configDir, device)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, path := range paths {
+		if err := os.Remove(path); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// PruneExpiredCommandCache removes every local command cache entry whose
+// expires_at has already passed, and returns how many were removed.
+func PruneExpiredCommandCache(configDir string) (int, error) {
+	paths, err := commandCacheFiles(configDir, "")
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	removed := 0
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var entry CommandCacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+		if now.After(entry.ExpiresAt) {
+			if err := os.Remove(path); err == nil {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
@@ -13,27 +13,35 @@ import (
 type AuthMode string
 
 const (
-	AuthModeNone   AuthMode = "none"
-	AuthModeAPIKey AuthMode = "api_key"
-	AuthModeJWT    AuthMode = "jwt"
+	AuthModeNone           AuthMode = "none"
+	AuthModeAPIKey         AuthMode = "api_key"
+	AuthModeJWT            AuthMode = "jwt"
+	AuthModeStaticPassword AuthMode = "static_password"
 )
 
 type Config struct {
-	Include           string   `json:"include,omitempty"` // exclusive with all other fields
-	APIURL            string   `json:"api_url,omitempty"`
-	AuthMode          AuthMode `json:"auth_mode"`
-	APIKey            string   `json:"api_key,omitempty"`
-	APIKeyHeader      string   `json:"api_key_header,omitempty"`
-	OAuthProvider     string   `json:"oauth_provider,omitempty"`
-	OAuthClientID     string   `json:"oauth_client_id,omitempty"`
-	OAuthClientSecret string   `json:"oauth_client_secret,omitempty"`
-	OAuthDiscoveryURL string   `json:"oauth_discovery_url,omitempty"`
-	OAuthRedirectPort int      `json:"oauth_redirect_port,omitempty"`
-	OAuthScopes       string   `json:"oauth_scopes,omitempty"`
-	OAuthUseRefresh   bool     `json:"oauth_use_refresh,omitempty"`
-	CacheEnabled      bool     `json:"cache_enabled,omitempty"`
-	CacheTTL          int      `json:"cache_ttl,omitempty"`
-	ConfigDir         string   `json:"-"`
+	Include                string   `json:"include,omitempty"` // exclusive with all other fields
+	APIURL                 string   `json:"api_url,omitempty"`
+	TomSocketPath          string   `json:"tom_socket_path,omitempty"` // unix domain socket path; takes precedence over APIURL when set
+	AuthMode               AuthMode `json:"auth_mode"`
+	APIKey                 string   `json:"api_key,omitempty"`
+	APIKeyHeader           string   `json:"api_key_header,omitempty"`
+	StaticPasswordUsername string   `json:"static_password_username,omitempty"`
+	StaticPasswordHash     string   `json:"static_password_hash,omitempty"`     // bcrypt hash; ignored if static_password_hash_env is set
+	StaticPasswordHashEnv  string   `json:"static_password_hash_env,omitempty"` // env var holding the bcrypt hash, e.g. "TOM_PASSWORD_HASH"
+	StaticPasswordEnv      string   `json:"static_password_env,omitempty"`      // env var holding the plaintext password to verify, default "TOM_PASSWORD"
+	OAuthProvider          string   `json:"oauth_provider,omitempty"`
+	OAuthClientID          string   `json:"oauth_client_id,omitempty"`
+	OAuthClientSecret      string   `json:"oauth_client_secret,omitempty"`
+	OAuthDiscoveryURL      string   `json:"oauth_discovery_url,omitempty"`
+	DeviceAuthURL          string   `json:"device_auth_url,omitempty"` // overrides discovery's device_authorization_endpoint
+	OAuthRedirectPort      int      `json:"oauth_redirect_port,omitempty"`
+	OAuthScopes            string   `json:"oauth_scopes,omitempty"`
+	OAuthUseRefresh        bool     `json:"oauth_use_refresh,omitempty"`
+	OAuthManualRedirect    bool     `json:"oauth_manual_redirect,omitempty"` // use urn:ietf:wg:oauth:2.0:oob and a pasted code instead of a loopback callback
+	CacheEnabled           bool     `json:"cache_enabled,omitempty"`
+	CacheTTL               int      `json:"cache_ttl,omitempty"`
+	ConfigDir              string   `json:"-"`
 }
 
 func (c *Config) GetAuthMode() string {
@@ -88,7 +96,20 @@ func GetConfigPath(configDir string) string {
 	return filepath.Join(configDir, "config.json")
 }
 
+// LoadConfig loads the active configuration from configDir, following
+// config.json's "include" field to the active profile, if any.
 func LoadConfig(configDir string) (*Config, error) {
+	return LoadConfigWithProfile(configDir, "")
+}
+
+// LoadConfigWithProfile loads the configuration the same way LoadConfig does,
+// except that profileOverride (when non-empty) selects which profile to load
+// for this invocation instead of config.json's "include" field - without
+// mutating config.json. If profileOverride is empty, the TOM_PROFILE
+// environment variable is checked before falling back to the include field,
+// giving a precedence order of: profileOverride > TOM_PROFILE > include field
+// > built-in defaults.
+func LoadConfigWithProfile(configDir, profileOverride string) (*Config, error) {
 	cfg := &Config{
 		ConfigDir:         configDir,
 		AuthMode:          AuthModeNone,
@@ -103,43 +124,62 @@ func LoadConfig(configDir string) (*Config, error) {
 		cfg.ConfigDir = GetConfigDir()
 	}
 
-	configPath := GetConfigPath(cfg.ConfigDir)
-	if _, err := os.Stat(configPath); err == nil {
-		data, err := os.ReadFile(configPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
-		}
-		if err := json.Unmarshal(data, cfg); err != nil {
-			return nil, fmt.Errorf("failed to parse config file: %w", err)
-		}
+	profile := profileOverride
+	if profile == "" {
+		profile = os.Getenv("TOM_PROFILE")
 	}
-	if cfg.Include != "" {
-		if cfg.Include == "config.json" {
-			return nil, fmt.Errorf("config include cannot be 'config.json'")
-		}
-		// Validate that include file matches config-*.json pattern
-		if !strings.HasPrefix(cfg.Include, "config-") || !strings.HasSuffix(cfg.Include, ".json") {
-			return nil, fmt.Errorf("config include must match pattern 'config-*.json', got '%s'", cfg.Include)
-		}
-		includePath := filepath.Join(cfg.ConfigDir, cfg.Include)
-		data, err := os.ReadFile(includePath)
+
+	if profile != "" {
+		loaded, err := LoadProfile(cfg.ConfigDir, profile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read included config file '%s': %w", cfg.Include, err)
+			return nil, fmt.Errorf("failed to load profile '%s': %w", profile, err)
 		}
-		if err := json.Unmarshal(data, cfg); err != nil {
-			return nil, fmt.Errorf("failed to parse included config file '%s': %w", cfg.Include, err)
+		cfg = loaded
+	} else {
+		configPath := GetConfigPath(cfg.ConfigDir)
+		if _, err := os.Stat(configPath); err == nil {
+			data, err := os.ReadFile(configPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read config file: %w", err)
+			}
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("failed to parse config file: %w", err)
+			}
+		}
+		if cfg.Include != "" {
+			if cfg.Include == "config.json" {
+				return nil, fmt.Errorf("config include cannot be 'config.json'")
+			}
+			// Validate that include file matches config-*.json pattern
+			if !strings.HasPrefix(cfg.Include, "config-") || !strings.HasSuffix(cfg.Include, ".json") {
+				return nil, fmt.Errorf("config include must match pattern 'config-*.json', got '%s'", cfg.Include)
+			}
+			includePath := filepath.Join(cfg.ConfigDir, cfg.Include)
+			data, err := os.ReadFile(includePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read included config file '%s': %w", cfg.Include, err)
+			}
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("failed to parse included config file '%s': %w", cfg.Include, err)
+			}
 		}
 	}
 
 	if apiURL := os.Getenv("TOM_API_URL"); apiURL != "" {
 		cfg.APIURL = apiURL
 	}
+	if socketPath := os.Getenv("TOM_SOCKET_PATH"); socketPath != "" {
+		cfg.TomSocketPath = socketPath
+	}
 	if authMode := os.Getenv("TOM_AUTH_MODE"); authMode != "" {
 		cfg.AuthMode = AuthMode(authMode)
 	}
 	if apiKey := os.Getenv("TOM_API_KEY"); apiKey != "" {
 		cfg.APIKey = apiKey
 	}
+	if username := os.Getenv("TOM_STATIC_PASSWORD_USERNAME"); username != "" {
+		cfg.StaticPasswordUsername = username
+	}
 	if provider := os.Getenv("TOM_OAUTH_PROVIDER"); provider != "" {
 		cfg.OAuthProvider = provider
 	}
@@ -152,6 +192,9 @@ func LoadConfig(configDir string) (*Config, error) {
 	if discoveryURL := os.Getenv("TOM_OAUTH_DISCOVERY_URL"); discoveryURL != "" {
 		cfg.OAuthDiscoveryURL = discoveryURL
 	}
+	if deviceAuthURL := os.Getenv("TOM_OAUTH_DEVICE_AUTH_URL"); deviceAuthURL != "" {
+		cfg.DeviceAuthURL = deviceAuthURL
+	}
 	if port := os.Getenv("TOM_OAUTH_REDIRECT_PORT"); port != "" {
 		fmt.Sscanf(port, "%d", &cfg.OAuthRedirectPort)
 	}
@@ -165,6 +208,13 @@ func LoadConfig(configDir string) (*Config, error) {
 			cfg.OAuthUseRefresh = false
 		}
 	}
+	if manualRedirect := os.Getenv("TOM_OAUTH_MANUAL_REDIRECT"); manualRedirect != "" {
+		if manualRedirect == "1" || manualRedirect == "true" || manualRedirect == "TRUE" {
+			cfg.OAuthManualRedirect = true
+		} else {
+			cfg.OAuthManualRedirect = false
+		}
+	}
 	if cacheEnabled := os.Getenv("TOM_CACHE_ENABLED"); cacheEnabled != "" {
 		if cacheEnabled == "0" || cacheEnabled == "false" || cacheEnabled == "FALSE" {
 			cfg.CacheEnabled = false
@@ -185,9 +235,21 @@ func LoadConfig(configDir string) (*Config, error) {
 		}
 	}
 
+	cfg.resolveSecretRefs()
+
 	return cfg, nil
 }
 
+// resolveSecretRefs resolves any "${env:FOO}" or "secret://<backend>/<key>"
+// indirections on secret-bearing fields, so client secrets and API keys
+// don't have to live in plaintext in config-*.json.
+func (c *Config) resolveSecretRefs() {
+	c.APIKey = resolveSecretRef(resolveEnvRef(c.APIKey), c.ConfigDir)
+	c.OAuthClientID = resolveEnvRef(c.OAuthClientID)
+	c.OAuthClientSecret = resolveSecretRef(resolveEnvRef(c.OAuthClientSecret), c.ConfigDir)
+	c.StaticPasswordHash = resolveSecretRef(resolveEnvRef(c.StaticPasswordHash), c.ConfigDir)
+}
+
 func (c *Config) Validate() error {
 	switch c.AuthMode {
 	case AuthModeNone:
@@ -230,17 +292,34 @@ func (c *Config) Validate() error {
 
 		return nil
 
+	case AuthModeStaticPassword:
+		if c.StaticPasswordUsername == "" {
+			return fmt.Errorf("auth_mode is 'static_password' but static_password_username is not set")
+		}
+		if c.StaticPasswordHash == "" && c.StaticPasswordHashEnv == "" {
+			return fmt.Errorf("auth_mode is 'static_password' but neither static_password_hash nor static_password_hash_env is set")
+		}
+		if c.APIURL == "" {
+			return fmt.Errorf("auth_mode is 'static_password' but api_url is not set (needed to reach /api/token)")
+		}
+		return nil
+
 	default:
-		return fmt.Errorf("invalid auth_mode '%s' - must be one of: none, api_key, jwt", c.AuthMode)
+		return fmt.Errorf("invalid auth_mode '%s' - must be one of: none, api_key, jwt, static_password", c.AuthMode)
 	}
 }
 
 func (c *Config) Save() error {
+	toSave, err := migrateSecrets(c)
+	if err != nil {
+		return err
+	}
+
 	if err := os.MkdirAll(c.ConfigDir, 0700); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	data, err := json.MarshalIndent(toSave, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
@@ -253,6 +332,43 @@ func (c *Config) Save() error {
 	return nil
 }
 
+// migrateSecrets returns a copy of cfg with plaintext api_key and
+// oauth_client_secret values replaced by "secret://<backend>/<key>"
+// references under the secret backend configured via SetSecretBackend (the
+// same marker SaveToken/LoadToken consult), storing the plaintext value in
+// that backend's SecretStore. Values already expressed as a reference or an
+// "${env:...}" indirection are left alone. cfg itself is untouched, so the
+// current process keeps using the plaintext value already loaded in memory.
+func migrateSecrets(cfg *Config) (*Config, error) {
+	backend := GetSecretBackend(cfg.ConfigDir)
+	if backend == "file" {
+		return cfg, nil
+	}
+
+	migrated := *cfg
+	store := NewSecretStore(backend, cfg.ConfigDir)
+
+	migrateField := func(value, key string) (string, error) {
+		if value == "" || strings.HasPrefix(value, "secret://") || strings.HasPrefix(value, "${env:") {
+			return value, nil
+		}
+		if err := store.Set(key, value); err != nil {
+			return "", fmt.Errorf("failed to migrate %s to '%s' secret store: %w", key, backend, err)
+		}
+		return fmt.Sprintf("secret://%s/%s", backend, key), nil
+	}
+
+	var err error
+	if migrated.APIKey, err = migrateField(migrated.APIKey, "api_key"); err != nil {
+		return nil, err
+	}
+	if migrated.OAuthClientSecret, err = migrateField(migrated.OAuthClientSecret, "oauth_client_secret"); err != nil {
+		return nil, err
+	}
+
+	return &migrated, nil
+}
+
 // ListProfiles returns a list of all config profile files in the config directory
 func ListProfiles(configDir string) ([]string, error) {
 	if configDir == "" {
@@ -390,7 +506,12 @@ func SaveProfile(cfg *Config, configDir, profileName string) error {
 	// Clear the Include field before saving to profile
 	cfg.Include = ""
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	toSave, err := migrateSecrets(cfg)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(toSave, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
@@ -403,6 +524,98 @@ func SaveProfile(cfg *Config, configDir, profileName string) error {
 	return nil
 }
 
+// DeleteProfile removes a profile file. If it is the currently active
+// profile, config.json's include field is cleared as well.
+func DeleteProfile(configDir, profileName string) error {
+	if configDir == "" {
+		configDir = GetConfigDir()
+	}
+
+	fileName := profileFileName(profileName)
+	profilePath := filepath.Join(configDir, fileName)
+	if _, err := os.Stat(profilePath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("profile '%s' does not exist", profileName)
+		}
+		return fmt.Errorf("failed to check profile file: %w", err)
+	}
+
+	current, err := GetCurrentProfile(configDir)
+	if err != nil {
+		return err
+	}
+	if current == profileName {
+		configPath := GetConfigPath(configDir)
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to clear active profile: %w", err)
+		}
+		var raw map[string]interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to clear active profile: %w", err)
+		}
+		delete(raw, "include")
+		if data, err = json.MarshalIndent(raw, "", "  "); err != nil {
+			return fmt.Errorf("failed to clear active profile: %w", err)
+		}
+		if err := os.WriteFile(configPath, data, 0600); err != nil {
+			return fmt.Errorf("failed to clear active profile: %w", err)
+		}
+	}
+
+	if err := os.Remove(profilePath); err != nil {
+		return fmt.Errorf("failed to delete profile file: %w", err)
+	}
+
+	return nil
+}
+
+// RenameProfile renames a profile file from oldName to newName, updating
+// config.json's include field if oldName is the currently active profile.
+func RenameProfile(configDir, oldName, newName string) error {
+	if configDir == "" {
+		configDir = GetConfigDir()
+	}
+
+	oldPath := filepath.Join(configDir, profileFileName(oldName))
+	newPath := filepath.Join(configDir, profileFileName(newName))
+
+	if _, err := os.Stat(oldPath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("profile '%s' does not exist", oldName)
+		}
+		return fmt.Errorf("failed to check profile file: %w", err)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("profile '%s' already exists", newName)
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to rename profile file: %w", err)
+	}
+
+	current, err := GetCurrentProfile(configDir)
+	if err != nil {
+		return err
+	}
+	if current == oldName {
+		return SetCurrentProfile(configDir, newName)
+	}
+
+	return nil
+}
+
+// profileFileName normalizes a profile name to its config-<name>.json filename.
+func profileFileName(profileName string) string {
+	if !strings.HasPrefix(profileName, "config-") {
+		profileName = "config-" + profileName
+	}
+	if !strings.HasSuffix(profileName, ".json") {
+		profileName = profileName + ".json"
+	}
+	return profileName
+}
+
 // LoadProfile loads a specific profile by name
 func LoadProfile(configDir, profileName string) (*Config, error) {
 	if configDir == "" {
@@ -438,5 +651,7 @@ func LoadProfile(configDir, profileName string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse profile file: %w", err)
 	}
 
+	cfg.resolveSecretRefs()
+
 	return cfg, nil
 }
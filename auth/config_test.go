@@ -48,3 +48,98 @@ func TestCacheEnvironmentVariables(t *testing.T) {
 		t.Errorf("Expected CacheTTL to be 600 from env var, got %d", cfg2.CacheTTL)
 	}
 }
+
+func TestLoadConfigWithProfileIsolation(t *testing.T) {
+	dir := t.TempDir()
+
+	prodCfg := &Config{ConfigDir: dir, AuthMode: AuthModeNone, APIURL: "https://prod.example.com"}
+	if err := SaveProfile(prodCfg, dir, "prod"); err != nil {
+		t.Fatalf("Failed to save prod profile: %v", err)
+	}
+
+	stagingCfg := &Config{ConfigDir: dir, AuthMode: AuthModeNone, APIURL: "https://staging.example.com"}
+	if err := SaveProfile(stagingCfg, dir, "staging"); err != nil {
+		t.Fatalf("Failed to save staging profile: %v", err)
+	}
+
+	if err := SetCurrentProfile(dir, "prod"); err != nil {
+		t.Fatalf("Failed to set current profile: %v", err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.APIURL != "https://prod.example.com" {
+		t.Errorf("Expected include field to select prod profile, got APIURL %q", cfg.APIURL)
+	}
+
+	// A one-off profile override must not mutate the active profile on disk.
+	cfg, err = LoadConfigWithProfile(dir, "staging")
+	if err != nil {
+		t.Fatalf("Failed to load config with profile override: %v", err)
+	}
+	if cfg.APIURL != "https://staging.example.com" {
+		t.Errorf("Expected profile override to select staging profile, got APIURL %q", cfg.APIURL)
+	}
+
+	current, err := GetCurrentProfile(dir)
+	if err != nil {
+		t.Fatalf("Failed to get current profile: %v", err)
+	}
+	if current != "prod" {
+		t.Errorf("Expected profile override to leave active profile untouched at 'prod', got %q", current)
+	}
+}
+
+func TestLoadConfigWithProfilePrecedence(t *testing.T) {
+	dir := t.TempDir()
+
+	includeCfg := &Config{ConfigDir: dir, AuthMode: AuthModeNone, APIURL: "https://include.example.com"}
+	if err := SaveProfile(includeCfg, dir, "include-profile"); err != nil {
+		t.Fatalf("Failed to save include-profile: %v", err)
+	}
+	if err := SetCurrentProfile(dir, "include-profile"); err != nil {
+		t.Fatalf("Failed to set current profile: %v", err)
+	}
+
+	envCfg := &Config{ConfigDir: dir, AuthMode: AuthModeNone, APIURL: "https://env.example.com"}
+	if err := SaveProfile(envCfg, dir, "env-profile"); err != nil {
+		t.Fatalf("Failed to save env-profile: %v", err)
+	}
+
+	flagCfg := &Config{ConfigDir: dir, AuthMode: AuthModeNone, APIURL: "https://flag.example.com"}
+	if err := SaveProfile(flagCfg, dir, "flag-profile"); err != nil {
+		t.Fatalf("Failed to save flag-profile: %v", err)
+	}
+
+	// With nothing set, the include field wins.
+	cfg, err := LoadConfigWithProfile(dir, "")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.APIURL != "https://include.example.com" {
+		t.Errorf("Expected include field to win with no flag/env set, got APIURL %q", cfg.APIURL)
+	}
+
+	// TOM_PROFILE env var overrides the include field.
+	os.Setenv("TOM_PROFILE", "env-profile")
+	defer os.Unsetenv("TOM_PROFILE")
+
+	cfg, err = LoadConfigWithProfile(dir, "")
+	if err != nil {
+		t.Fatalf("Failed to load config with TOM_PROFILE set: %v", err)
+	}
+	if cfg.APIURL != "https://env.example.com" {
+		t.Errorf("Expected TOM_PROFILE to override include field, got APIURL %q", cfg.APIURL)
+	}
+
+	// The explicit override (standing in for the --profile flag) beats TOM_PROFILE.
+	cfg, err = LoadConfigWithProfile(dir, "flag-profile")
+	if err != nil {
+		t.Fatalf("Failed to load config with profile override set: %v", err)
+	}
+	if cfg.APIURL != "https://flag.example.com" {
+		t.Errorf("Expected profile override to beat TOM_PROFILE, got APIURL %q", cfg.APIURL)
+	}
+}
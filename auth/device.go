@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"tomclient/auth/providers"
+)
+
+// deviceCodeProvider is implemented by providers that support the RFC 8628
+// device authorization grant in addition to the authorization-code+PKCE flow.
+type deviceCodeProvider interface {
+	BuildDeviceAuthRequest(clientID, scope string) url.Values
+	BuildDeviceTokenRequest(deviceCode, clientID, clientSecret string) url.Values
+}
+
+// DeviceAuthResponse is the response from a device authorization endpoint.
+type DeviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// deviceTokenError captures the error/error_description shape returned by the
+// token endpoint while the user has not yet completed the device flow.
+type deviceTokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// resolveDeviceAuthURL returns the device authorization endpoint to use,
+// preferring an explicit config override before falling back to OIDC discovery.
+func resolveDeviceAuthURL(config *Config) (string, error) {
+	if config.DeviceAuthURL != "" {
+		return config.DeviceAuthURL, nil
+	}
+
+	discovery, err := discoverOIDCEndpoints(context.Background(), config.OAuthDiscoveryURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch OIDC discovery from %s: %w", config.OAuthDiscoveryURL, err)
+	}
+
+	if discovery.DeviceAuthorizationEndpoint == "" {
+		return "", fmt.Errorf("OIDC discovery document does not advertise a device_authorization_endpoint; set device_auth_url")
+	}
+
+	return discovery.DeviceAuthorizationEndpoint, nil
+}
+
+// startDeviceAuth POSTs to the device authorization endpoint and returns the
+// device_code/user_code/verification_uri needed to complete the flow.
+func startDeviceAuth(endpoint string, provider deviceCodeProvider, clientID, scope string) (*DeviceAuthResponse, error) {
+	resp, err := http.PostForm(endpoint, provider.BuildDeviceAuthRequest(clientID, scope))
+	if err != nil {
+		return nil, fmt.Errorf("device authorization request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("device authorization request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var auth DeviceAuthResponse
+	if err := json.Unmarshal(body, &auth); err != nil {
+		return nil, fmt.Errorf("failed to parse device authorization response: %w", err)
+	}
+
+	if auth.DeviceCode == "" || auth.UserCode == "" {
+		return nil, fmt.Errorf("device authorization response missing device_code/user_code")
+	}
+	if auth.Interval <= 0 {
+		auth.Interval = 5
+	}
+
+	return &auth, nil
+}
+
+// pollDeviceToken polls the token endpoint per RFC 8628 until the user
+// completes the flow, the device code expires, or access is denied.
+func pollDeviceToken(tokenEndpoint string, provider deviceCodeProvider, auth *DeviceAuthResponse, clientID, clientSecret string) (*TokenResponse, error) {
+	interval := time.Duration(auth.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+
+	for {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("device code expired before authorization completed")
+		}
+
+		time.Sleep(interval)
+
+		data := provider.BuildDeviceTokenRequest(auth.DeviceCode, clientID, clientSecret)
+		resp, err := http.PostForm(tokenEndpoint, data)
+		if err != nil {
+			return nil, fmt.Errorf("device token request failed: %w", err)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == 200 {
+			var token TokenResponse
+			if err := json.Unmarshal(body, &token); err != nil {
+				return nil, fmt.Errorf("failed to parse token response: %w", err)
+			}
+			return &token, nil
+		}
+
+		var tokenErr deviceTokenError
+		if err := json.Unmarshal(body, &tokenErr); err != nil {
+			return nil, fmt.Errorf("device token request failed with status %d: %s", resp.StatusCode, string(body))
+		}
+
+		switch tokenErr.Error {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		case "access_denied":
+			return nil, fmt.Errorf("authorization denied by user")
+		case "expired_token":
+			return nil, fmt.Errorf("device code expired before authorization completed")
+		default:
+			return nil, fmt.Errorf("device token request failed: %s: %s", tokenErr.Error, tokenErr.ErrorDescription)
+		}
+	}
+}
+
+// AuthenticateDevice runs the RFC 8628 device authorization grant: it obtains
+// a user code, prompts the user to visit the verification URL, then polls the
+// token endpoint until the user completes (or abandons) the flow.
+func AuthenticateDevice(config *Config) error {
+	provider, err := providers.GetProvider(config.OAuthProvider)
+	if err != nil {
+		return err
+	}
+
+	dcProvider, ok := provider.(deviceCodeProvider)
+	if !ok {
+		return fmt.Errorf("OAuth provider '%s' does not support the device authorization grant", config.OAuthProvider)
+	}
+
+	deviceAuthURL, err := resolveDeviceAuthURL(config)
+	if err != nil {
+		return err
+	}
+
+	discovery, err := discoverOIDCEndpoints(context.Background(), config.OAuthDiscoveryURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch OIDC discovery from %s: %w", config.OAuthDiscoveryURL, err)
+	}
+
+	auth, err := startDeviceAuth(deviceAuthURL, dcProvider, config.OAuthClientID, config.OAuthScopes)
+	if err != nil {
+		return fmt.Errorf("failed to start device authorization: %w", err)
+	}
+
+	if auth.VerificationURIComplete != "" {
+		fmt.Printf("To authenticate, visit:\n\n  %s\n\n", auth.VerificationURIComplete)
+	} else {
+		fmt.Printf("To authenticate, visit:\n\n  %s\n\nand enter code: %s\n\n", auth.VerificationURI, auth.UserCode)
+	}
+	fmt.Println("Waiting for authorization...")
+
+	token, err := pollDeviceToken(discovery.TokenEndpoint, dcProvider, auth, config.OAuthClientID, config.OAuthClientSecret)
+	if err != nil {
+		return fmt.Errorf("failed to complete device authorization: %w", err)
+	}
+
+	if token.IDToken != "" && discovery.JwksURI != "" {
+		// The device flow has no per-flow nonce to compare against, same as
+		// RefreshAccessToken's ID token verification.
+		if _, err := VerifyIDToken(token.IDToken, config.OAuthClientID, "", discovery); err != nil {
+			return fmt.Errorf("failed to verify ID token: %w", err)
+		}
+	}
+
+	if err := SaveToken(token, config.ConfigDir, config.OAuthProvider); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+
+	fmt.Println("✅ Authentication successful!")
+	return nil
+}
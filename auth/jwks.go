@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// claimSkewLeeway tolerates small clock drift between this host and the
+// provider when checking exp/iat/nbf, consistent with the leeway already
+// used for access/ID token expiry elsewhere in this package.
+const claimSkewLeeway = 60 * time.Second
+
+// JWK is a single entry in a JSON Web Key Set, as published at an OIDC
+// provider's jwks_uri. Only the fields needed to reconstruct an RSA or EC
+// public key are modeled.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is the document served at jwks_uri.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwksCacheEntry holds a previously fetched key set alongside when it stops
+// being fresh, per the response's Cache-Control max-age.
+type jwksCacheEntry struct {
+	set       *JWKSet
+	expiresAt time.Time
+}
+
+var (
+	jwksCacheMu sync.Mutex
+	jwksCache   = map[string]jwksCacheEntry{}
+)
+
+// fetchJWKS retrieves and parses the JSON Web Key Set at jwksURI, reusing a
+// cached copy while it's within the response's Cache-Control max-age so a
+// burst of token refreshes doesn't re-fetch the key set every time.
+func fetchJWKS(jwksURI string) (*JWKSet, error) {
+	jwksCacheMu.Lock()
+	if entry, ok := jwksCache[jwksURI]; ok && time.Now().Before(entry.expiresAt) {
+		jwksCacheMu.Unlock()
+		return entry.set, nil
+	}
+	jwksCacheMu.Unlock()
+
+	resp, err := http.Get(jwksURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("JWKS fetch failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	maxAge := parseMaxAge(resp.Header.Get("Cache-Control"))
+
+	var set JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	if maxAge > 0 {
+		jwksCacheMu.Lock()
+		jwksCache[jwksURI] = jwksCacheEntry{set: &set, expiresAt: time.Now().Add(maxAge)}
+		jwksCacheMu.Unlock()
+	}
+
+	return &set, nil
+}
+
+// parseMaxAge extracts max-age from a Cache-Control header value, returning
+// 0 (don't cache) if absent or unparsable.
+func parseMaxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		if name, value, ok := strings.Cut(directive, "="); ok && strings.EqualFold(name, "max-age") {
+			if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return 0
+}
+
+// rsaPublicKey reconstructs the RSA public key encoded by a JWK.
+func (k JWK) rsaPublicKey() (*rsa.PublicKey, error) {
+	if k.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported key type %q, only RSA is supported", k.Kty)
+	}
+
+	nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
+
+// ecdsaPublicKey reconstructs the EC public key encoded by a JWK. Only the
+// P-256 curve (crv="P-256", used by ES256) is supported.
+func (k JWK) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	if k.Kty != "EC" {
+		return nil, fmt.Errorf("unsupported key type %q, only EC is supported here", k.Kty)
+	}
+	if k.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported EC curve %q, only P-256 is supported", k.Crv)
+	}
+
+	xBytes, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode x coordinate: %w", err)
+	}
+	yBytes, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode y coordinate: %w", err)
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(xBytes),
+		Y:     new(big.Int).SetBytes(yBytes),
+	}, nil
+}
+
+// jwtHeader is the subset of JOSE header fields needed to pick a verification key.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// IDTokenClaims holds the claims VerifyIDToken decodes out of a verified ID
+// token. VerifyIDToken itself enforces audience, and nonce when given one.
+type IDTokenClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Audience  string `json:"aud"`
+	Expiry    int64  `json:"exp"`
+	IssuedAt  int64  `json:"iat"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	Nonce     string `json:"nonce,omitempty"`
+}
+
+// VerifyIDToken verifies an OIDC ID token's signature (RS256 or ES256)
+// against the provider's JWKS and checks that its iss/aud/exp/iat/nbf claims
+// are acceptable for clientID, returning the decoded claims on success. When
+// expectedNonce is non-empty, the token's nonce claim must match it exactly;
+// pass "" to skip the check (e.g. for a refresh response, which carries no
+// fresh per-flow nonce to compare against). Providers signing with anything
+// other than RS256/ES256 fail closed.
+func VerifyIDToken(idToken, clientID, expectedNonce string, discovery *OIDCDiscovery) (*IDTokenClaims, error) {
+	if discovery.JwksURI == "" {
+		return nil, fmt.Errorf("provider did not publish a jwks_uri, cannot verify ID token signature")
+	}
+
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed ID token: expected 3 segments, got %d", len(parts))
+	}
+	headerB64, payloadB64, sigB64 := parts[0], parts[1], parts[2]
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(headerB64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ID token header: %w", err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse ID token header: %w", err)
+	}
+	if header.Alg != "RS256" && header.Alg != "ES256" {
+		return nil, fmt.Errorf("unsupported ID token signing algorithm %q, only RS256 and ES256 are supported", header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(sigB64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ID token signature: %w", err)
+	}
+
+	jwks, err := fetchJWKS(discovery.JwksURI)
+	if err != nil {
+		return nil, err
+	}
+
+	signingInput := headerB64 + "." + payloadB64
+	hashed := sha256.Sum256([]byte(signingInput))
+
+	var verified bool
+	for _, key := range jwks.Keys {
+		if header.Kid != "" && key.Kid != header.Kid {
+			continue
+		}
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch header.Alg {
+		case "RS256":
+			pubKey, err := key.rsaPublicKey()
+			if err != nil {
+				continue
+			}
+			if err := rsa.VerifyPKCS1v15(pubKey, crypto.SHA256, hashed[:], sig); err != nil {
+				continue
+			}
+		case "ES256":
+			pubKey, err := key.ecdsaPublicKey()
+			if err != nil {
+				continue
+			}
+			if len(sig) != 64 {
+				continue
+			}
+			r := new(big.Int).SetBytes(sig[:32])
+			s := new(big.Int).SetBytes(sig[32:])
+			if !ecdsa.Verify(pubKey, hashed[:], r, s) {
+				continue
+			}
+		}
+
+		verified = true
+		break
+	}
+	if !verified {
+		return nil, fmt.Errorf("ID token signature verification failed: no matching JWKS key for kid %q", header.Kid)
+	}
+
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(payloadB64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ID token payload: %w", err)
+	}
+	var claims IDTokenClaims
+	if err := json.Unmarshal(payloadJSON, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse ID token claims: %w", err)
+	}
+
+	if claims.Issuer != discovery.Issuer {
+		return nil, fmt.Errorf("ID token issuer %q does not match discovery issuer %q", claims.Issuer, discovery.Issuer)
+	}
+	if claims.Audience != clientID {
+		return nil, fmt.Errorf("ID token audience %q does not match client ID %q", claims.Audience, clientID)
+	}
+
+	now := time.Now()
+	if claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(claimSkewLeeway)) {
+		return nil, fmt.Errorf("ID token is expired")
+	}
+	if claims.IssuedAt != 0 && now.Before(time.Unix(claims.IssuedAt, 0).Add(-claimSkewLeeway)) {
+		return nil, fmt.Errorf("ID token iat is in the future")
+	}
+	if claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0).Add(-claimSkewLeeway)) {
+		return nil, fmt.Errorf("ID token is not valid yet (nbf)")
+	}
+
+	if expectedNonce != "" && claims.Nonce != expectedNonce {
+		return nil, fmt.Errorf("ID token nonce does not match the authorization request")
+	}
+
+	return &claims, nil
+}
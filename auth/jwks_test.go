@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// idTokenFixture builds and signs an RS256 ID token for VerifyIDToken tests,
+// serving the matching JWKS from an httptest.Server.
+type idTokenFixture struct {
+	t        *testing.T
+	key      *rsa.PrivateKey
+	jwksSrv  *httptest.Server
+	issuer   string
+	clientID string
+}
+
+func newIDTokenFixture(t *testing.T) *idTokenFixture {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	jwk := JWK{
+		Kty: "RSA",
+		Kid: "test-key",
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(rsaExponentBytes(key.PublicKey.E)),
+	}
+
+	jwksSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(JWKSet{Keys: []JWK{jwk}})
+	}))
+	t.Cleanup(jwksSrv.Close)
+
+	return &idTokenFixture{
+		t:        t,
+		key:      key,
+		jwksSrv:  jwksSrv,
+		issuer:   "https://issuer.example.com",
+		clientID: "test-client",
+	}
+}
+
+// rsaExponentBytes trims an RSA public exponent (typically 65537) down to its
+// minimal big-endian byte representation for JWK's base64url "e" field.
+func rsaExponentBytes(e int) []byte {
+	b := []byte{byte(e >> 16), byte(e >> 8), byte(e)}
+	i := 0
+	for i < len(b)-1 && b[i] == 0 {
+		i++
+	}
+	return b[i:]
+}
+
+func (f *idTokenFixture) discovery() *OIDCDiscovery {
+	return &OIDCDiscovery{Issuer: f.issuer, JwksURI: f.jwksSrv.URL}
+}
+
+// sign builds a compact RS256 JWT over claims, overridable via overrides for
+// tests that need a malformed or expired claim set.
+func (f *idTokenFixture) sign(overrides map[string]interface{}) string {
+	f.t.Helper()
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":   f.issuer,
+		"aud":   f.clientID,
+		"sub":   "user-123",
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+		"nonce": "expected-nonce",
+	}
+	for k, v := range overrides {
+		claims[k] = v
+	}
+
+	header := map[string]string{"alg": "RS256", "kid": "test-key"}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		f.t.Fatalf("failed to marshal header: %v", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		f.t.Fatalf("failed to marshal claims: %v", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	hashed := sha256.Sum256([]byte(signingInput))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		f.t.Fatalf("failed to sign token: %v", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifyIDTokenAccepts(t *testing.T) {
+	f := newIDTokenFixture(t)
+	token := f.sign(nil)
+
+	claims, err := VerifyIDToken(token, f.clientID, "expected-nonce", f.discovery())
+	if err != nil {
+		t.Fatalf("expected valid token to verify, got: %v", err)
+	}
+	if claims.Subject != "user-123" {
+		t.Errorf("expected subject %q, got %q", "user-123", claims.Subject)
+	}
+}
+
+func TestVerifyIDTokenRejectsWrongAudience(t *testing.T) {
+	f := newIDTokenFixture(t)
+	token := f.sign(map[string]interface{}{"aud": "someone-else"})
+
+	if _, err := VerifyIDToken(token, f.clientID, "expected-nonce", f.discovery()); err == nil {
+		t.Fatal("expected audience mismatch to fail verification")
+	}
+}
+
+func TestVerifyIDTokenRejectsExpired(t *testing.T) {
+	f := newIDTokenFixture(t)
+	token := f.sign(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})
+
+	if _, err := VerifyIDToken(token, f.clientID, "expected-nonce", f.discovery()); err == nil {
+		t.Fatal("expected expired token to fail verification")
+	}
+}
+
+func TestVerifyIDTokenRejectsFutureNotBefore(t *testing.T) {
+	f := newIDTokenFixture(t)
+	token := f.sign(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()})
+
+	if _, err := VerifyIDToken(token, f.clientID, "expected-nonce", f.discovery()); err == nil {
+		t.Fatal("expected not-yet-valid (nbf) token to fail verification")
+	}
+}
+
+func TestVerifyIDTokenRejectsNonceMismatch(t *testing.T) {
+	f := newIDTokenFixture(t)
+	token := f.sign(nil)
+
+	if _, err := VerifyIDToken(token, f.clientID, "a-different-nonce", f.discovery()); err == nil {
+		t.Fatal("expected nonce mismatch to fail verification")
+	}
+}
+
+func TestVerifyIDTokenSkipsNonceCheckWhenExpectedEmpty(t *testing.T) {
+	f := newIDTokenFixture(t)
+	token := f.sign(nil)
+
+	if _, err := VerifyIDToken(token, f.clientID, "", f.discovery()); err != nil {
+		t.Fatalf("expected empty expectedNonce to skip the nonce check, got: %v", err)
+	}
+}
+
+func TestVerifyIDTokenRejectsWrongIssuer(t *testing.T) {
+	f := newIDTokenFixture(t)
+	token := f.sign(map[string]interface{}{"iss": "https://not-the-issuer.example.com"})
+
+	if _, err := VerifyIDToken(token, f.clientID, "expected-nonce", f.discovery()); err == nil {
+		t.Fatal("expected issuer mismatch to fail verification")
+	}
+}
+
+func TestVerifyIDTokenRejectsTamperedSignature(t *testing.T) {
+	f := newIDTokenFixture(t)
+	token := f.sign(nil)
+	tampered := token[:len(token)-4] + "abcd"
+
+	if _, err := VerifyIDToken(tampered, f.clientID, "expected-nonce", f.discovery()); err == nil {
+		t.Fatal("expected tampered signature to fail verification")
+	}
+}
+
+func TestVerifyIDTokenRejectsUnsupportedAlg(t *testing.T) {
+	f := newIDTokenFixture(t)
+
+	header := map[string]string{"alg": "HS256", "kid": "test-key"}
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(map[string]interface{}{"iss": f.issuer, "aud": f.clientID})
+	token := fmt.Sprintf("%s.%s.%s",
+		base64.RawURLEncoding.EncodeToString(headerJSON),
+		base64.RawURLEncoding.EncodeToString(claimsJSON),
+		base64.RawURLEncoding.EncodeToString([]byte("fake-signature")))
+
+	if _, err := VerifyIDToken(token, f.clientID, "", f.discovery()); err == nil {
+		t.Fatal("expected HS256 (unsupported algorithm) to fail closed")
+	}
+}
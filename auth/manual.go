@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// oobRedirectURI is the out-of-band redirect used by OAuthManualRedirect
+// mode, following the urn:ietf:wg:oauth:2.0:oob convention used by earlier
+// installed-app OAuth clients: the provider renders the authorization code
+// on its own page instead of redirecting back to a loopback callback, and
+// the user copies it into the terminal.
+const oobRedirectURI = "urn:ietf:wg:oauth:2.0:oob"
+
+// redirectURI returns the redirect_uri to use for both the authorization
+// request and the token exchange, so GetAuthURL and ExchangeCodeForToken
+// always agree: the OOB URI in manual mode, the loopback callback otherwise.
+func (f *OAuthFlow) redirectURI() string {
+	if f.Config.OAuthManualRedirect {
+		return oobRedirectURI
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d/callback", f.CallbackPort)
+}
+
+// PromptForCode reads the authorization code the user pastes from the
+// provider's page, used instead of the CallbackListener when
+// OAuthManualRedirect is set - there is no loopback callback to listen on.
+func (f *OAuthFlow) PromptForCode() (string, error) {
+	fmt.Print("Enter the authorization code: ")
+
+	reader := bufio.NewReader(os.Stdin)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read authorization code: %w", err)
+	}
+
+	code := strings.TrimSpace(line)
+	if code == "" {
+		return "", fmt.Errorf("no authorization code entered")
+	}
+	return code, nil
+}
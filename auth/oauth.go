@@ -5,34 +5,62 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os/exec"
 	"runtime"
+	"strings"
 	"time"
 
 	"tomclient/auth/providers"
 )
 
+// ErrInvalidGrant marks a refresh attempt the authorization server rejected
+// outright (expired, revoked, or already-used refresh token). Callers should
+// treat this as "no amount of retrying will help" and fall back to a full
+// re-authentication instead of surfacing it as a transient failure.
+var ErrInvalidGrant = errors.New("invalid_grant")
+
+// oauthErrorResponse is the RFC 6749 §5.2 error body shape.
+type oauthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
 type OIDCDiscovery struct {
-	AuthorizationEndpoint string `json:"authorization_endpoint"`
-	TokenEndpoint         string `json:"token_endpoint"`
-	JwksURI               string `json:"jwks_uri"`
-	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	JwksURI                     string `json:"jwks_uri"`
+	Issuer                      string `json:"issuer"`
 }
 
 type OAuthFlow struct {
 	Config       *Config
 	CodeVerifier string
 	State        string
+	Nonce        string
 	Discovery    *OIDCDiscovery
 	Provider     providers.Provider
+
+	// CallbackPort is the port redirectURI advertises for the loopback
+	// callback. It defaults to Config.OAuthRedirectPort, but Authenticate
+	// overwrites it with the CallbackListener's actual bound port once that's
+	// known, so Config.OAuthRedirectPort == 0 (OS-assigned port) round-trips
+	// correctly into the authorization URL.
+	CallbackPort int
 }
 
-func discoverOIDCEndpoints(discoveryURL string) (*OIDCDiscovery, error) {
-	resp, err := http.Get(discoveryURL)
+func discoverOIDCEndpoints(ctx context.Context, discoveryURL string) (*OIDCDiscovery, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OIDC discovery request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
 	}
@@ -66,7 +94,12 @@ func NewOAuthFlow(config *Config) (*OAuthFlow, error) {
 		return nil, err
 	}
 
-	discovery, err := discoverOIDCEndpoints(config.OAuthDiscoveryURL)
+	nonce, err := generateState()
+	if err != nil {
+		return nil, err
+	}
+
+	discovery, err := discoverOIDCEndpoints(context.Background(), config.OAuthDiscoveryURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch OIDC discovery from %s: %w", config.OAuthDiscoveryURL, err)
 	}
@@ -80,8 +113,10 @@ func NewOAuthFlow(config *Config) (*OAuthFlow, error) {
 		Config:       config,
 		CodeVerifier: verifier,
 		State:        state,
+		Nonce:        nonce,
 		Discovery:    discovery,
 		Provider:     provider,
+		CallbackPort: config.OAuthRedirectPort,
 	}, nil
 }
 
@@ -99,84 +134,24 @@ func (f *OAuthFlow) GetAuthURL() string {
 	params := url.Values{
 		"response_type":         {"code"},
 		"client_id":             {f.Config.OAuthClientID},
-		"redirect_uri":          {fmt.Sprintf("http://localhost:%d/callback", f.Config.OAuthRedirectPort)},
+		"redirect_uri":          {f.redirectURI()},
 		"code_challenge":        {challenge},
 		"code_challenge_method": {"S256"},
 		"scope":                 {f.Config.OAuthScopes},
 		"state":                 {f.State},
+		"nonce":                 {f.Nonce},
 	}
 
 	return fmt.Sprintf("%s?%s", f.Discovery.AuthorizationEndpoint, params.Encode())
 }
 
-func (f *OAuthFlow) StartCallbackServer(ctx context.Context) (string, error) {
-	codeChan := make(chan string, 1)
-	errChan := make(chan error, 1)
-	stateChan := make(chan string, 1)
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
-		state := r.URL.Query().Get("state")
-		errorParam := r.URL.Query().Get("error")
-
-		if errorParam != "" {
-			errChan <- fmt.Errorf("OAuth error: %s", errorParam)
-			w.Header().Set("Content-Type", "text/html")
-			fmt.Fprintf(w, `<html><body><h1>Authentication Failed</h1><p>Error: %s</p></body></html>`, errorParam)
-			return
-		}
-
-		if code == "" {
-			errChan <- fmt.Errorf("no authorization code received")
-			w.Header().Set("Content-Type", "text/html")
-			fmt.Fprint(w, `<html><body><h1>Authentication Failed</h1><p>No authorization code received</p></body></html>`)
-			return
-		}
-
-		stateChan <- state
-		codeChan <- code
-		w.Header().Set("Content-Type", "text/html")
-		fmt.Fprint(w, `<html><body><h1>Authentication Successful!</h1><p>You can close this window and return to your terminal.</p><script>window.setTimeout(function(){window.close()}, 2000);</script></body></html>`)
-	})
-
-	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", f.Config.OAuthRedirectPort),
-		Handler: mux,
-	}
-
-	go func() {
-		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-			errChan <- err
-		}
-	}()
-
-	defer srv.Shutdown(context.Background())
-
-	select {
-	case code := <-codeChan:
-		receivedState := <-stateChan
-		if receivedState != f.State {
-			return "", fmt.Errorf("state mismatch - possible CSRF attack")
-		}
-		return code, nil
-	case err := <-errChan:
-		return "", err
-	case <-ctx.Done():
-		return "", fmt.Errorf("authentication timed out")
-	case <-time.After(2 * time.Minute):
-		return "", fmt.Errorf("authentication timed out after 2 minutes")
-	}
-}
-
 func (f *OAuthFlow) ExchangeCodeForToken(code string) (*TokenResponse, error) {
-	redirectURI := fmt.Sprintf("http://localhost:%d/callback", f.Config.OAuthRedirectPort)
 	data := f.Provider.BuildTokenRequest(
 		code,
 		f.CodeVerifier,
 		f.Config.OAuthClientID,
 		f.Config.OAuthClientSecret,
-		redirectURI,
+		f.redirectURI(),
 	)
 
 	resp, err := http.PostForm(f.Discovery.TokenEndpoint, data)
@@ -199,6 +174,66 @@ func (f *OAuthFlow) ExchangeCodeForToken(code string) (*TokenResponse, error) {
 	return &token, nil
 }
 
+// RefreshAccessToken exchanges a refresh token for a new access/ID token
+// using the provider's refresh request builder, re-discovering the token
+// endpoint the same way NewOAuthFlow does rather than requiring a live
+// OAuthFlow. Returns ErrInvalidGrant when the authorization server rejects
+// the refresh token itself, so callers can distinguish "the refresh token is
+// dead, re-authenticate" from a transient request failure. ctx bounds both
+// the discovery fetch and the refresh request, so a hung authorization
+// server can't block a refresh indefinitely.
+func RefreshAccessToken(ctx context.Context, config *Config, refreshToken string) (*TokenResponse, error) {
+	discovery, err := discoverOIDCEndpoints(ctx, config.OAuthDiscoveryURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery from %s: %w", config.OAuthDiscoveryURL, err)
+	}
+
+	provider, err := providers.GetProvider(config.OAuthProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	data := provider.BuildRefreshRequest(refreshToken, config.OAuthClientID, config.OAuthClientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build refresh request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("refresh request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != 200 {
+		var oauthErr oauthErrorResponse
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error == "invalid_grant" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidGrant, oauthErr.ErrorDescription)
+		}
+		return nil, fmt.Errorf("refresh failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var token TokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse refresh response: %w", err)
+	}
+
+	if token.IDToken != "" && discovery.JwksURI != "" {
+		// A refreshed ID token carries no fresh nonce to compare against, so
+		// nonce enforcement only applies to the initial authorization-code
+		// exchange in Authenticate.
+		if _, err := VerifyIDToken(token.IDToken, config.OAuthClientID, "", discovery); err != nil {
+			return nil, fmt.Errorf("failed to verify refreshed ID token: %w", err)
+		}
+	}
+
+	return &token, nil
+}
+
 func openBrowser(url string) error {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
@@ -220,6 +255,19 @@ func Authenticate(config *Config) error {
 		return fmt.Errorf("failed to initialize OAuth flow: %w", err)
 	}
 
+	// The listener must be bound before GetAuthURL runs: when
+	// OAuthRedirectPort is 0 the OS picks the port, and redirectURI needs
+	// that actual port to build a redirect_uri the token exchange can match.
+	var listener *CallbackListener
+	if !config.OAuthManualRedirect {
+		listener, err = NewCallbackListener(config.OAuthRedirectPort)
+		if err != nil {
+			return fmt.Errorf("failed to start callback listener: %w", err)
+		}
+		defer listener.Shutdown()
+		flow.CallbackPort = listener.Port()
+	}
+
 	authURL := flow.GetAuthURL()
 
 	fmt.Println("Opening browser for authentication...")
@@ -227,17 +275,37 @@ func Authenticate(config *Config) error {
 
 	if err := openBrowser(authURL); err != nil {
 		fmt.Printf("Could not open browser automatically: %v\n", err)
+
+		if !config.OAuthManualRedirect {
+			if provider, perr := providers.GetProvider(config.OAuthProvider); perr == nil {
+				if _, ok := provider.(deviceCodeProvider); ok {
+					fmt.Println("Falling back to the device authorization grant...")
+					return AuthenticateDevice(config)
+				}
+			}
+		}
+
 		fmt.Println("Please copy and paste the URL above into your browser.")
 	}
 
-	fmt.Printf("Waiting for authentication (listening on http://localhost:%d/callback)...\n", config.OAuthRedirectPort)
+	var code string
+	if config.OAuthManualRedirect {
+		code, err = flow.PromptForCode()
+		if err != nil {
+			return fmt.Errorf("failed to read authorization code: %w", err)
+		}
+	} else {
+		fmt.Printf("Waiting for authentication (listening on http://127.0.0.1:%d/callback)...\n", flow.CallbackPort)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-	defer cancel()
+		listener.Serve()
 
-	code, err := flow.StartCallbackServer(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to receive authorization code: %w", err)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		code, err = listener.Wait(ctx, flow.State, 2*time.Minute)
+		if err != nil {
+			return fmt.Errorf("failed to receive authorization code: %w", err)
+		}
 	}
 
 	fmt.Println("Authorization code received, exchanging for token...")
@@ -247,6 +315,12 @@ func Authenticate(config *Config) error {
 		return fmt.Errorf("failed to exchange code for token: %w", err)
 	}
 
+	if token.IDToken != "" && flow.Discovery.JwksURI != "" {
+		if _, err := VerifyIDToken(token.IDToken, config.OAuthClientID, flow.Nonce, flow.Discovery); err != nil {
+			return fmt.Errorf("failed to verify ID token: %w", err)
+		}
+	}
+
 	if err := SaveToken(token, config.ConfigDir, config.OAuthProvider); err != nil {
 		return fmt.Errorf("failed to save token: %w", err)
 	}
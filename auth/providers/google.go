@@ -44,3 +44,23 @@ func (p *GoogleProvider) AuthURLParams() url.Values {
 	}
 	return vals
 }
+
+// BuildDeviceAuthRequest builds the form body for RFC 8628 device authorization requests.
+func (p *GoogleProvider) BuildDeviceAuthRequest(clientID, scope string) url.Values {
+	return url.Values{
+		"client_id": {clientID},
+		"scope":     {scope},
+	}
+}
+
+// BuildDeviceTokenRequest builds the form body for polling the token endpoint
+// during the RFC 8628 device authorization grant. Google requires the client
+// secret here even for installed-app clients that omit it elsewhere.
+func (p *GoogleProvider) BuildDeviceTokenRequest(deviceCode, clientID, clientSecret string) url.Values {
+	return url.Values{
+		"grant_type":    {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code":   {deviceCode},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+	}
+}
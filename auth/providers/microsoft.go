@@ -43,3 +43,25 @@ func (p *MicrosoftProvider) BuildRefreshRequest(refreshToken, clientID, clientSe
 func (p *MicrosoftProvider) AuthURLParams() url.Values {
 	return url.Values{}
 }
+
+// BuildDeviceAuthRequest builds the form body for RFC 8628 device authorization requests.
+func (p *MicrosoftProvider) BuildDeviceAuthRequest(clientID, scope string) url.Values {
+	return url.Values{
+		"client_id": {clientID},
+		"scope":     {scope},
+	}
+}
+
+// BuildDeviceTokenRequest builds the form body for polling the token endpoint
+// during the RFC 8628 device authorization grant.
+func (p *MicrosoftProvider) BuildDeviceTokenRequest(deviceCode, clientID, clientSecret string) url.Values {
+	vals := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {deviceCode},
+		"client_id":   {clientID},
+	}
+	if clientSecret != "" {
+		vals.Set("client_secret", clientSecret)
+	}
+	return vals
+}
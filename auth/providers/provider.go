@@ -21,7 +21,9 @@ func GetProvider(name string) (Provider, error) {
 		return &GoogleProvider{}, nil
 	case "microsoft":
 		return &MicrosoftProvider{}, nil
+	case "okta":
+		return &OktaProvider{}, nil
 	default:
-		return nil, fmt.Errorf("unknown OAuth provider '%s' - must be one of: oidc, google, microsoft", name)
+		return nil, fmt.Errorf("unknown OAuth provider '%s' - must be one of: oidc, google, microsoft, okta", name)
 	}
 }
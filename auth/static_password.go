@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// resolveStaticPasswordHash returns the configured bcrypt hash, preferring
+// the environment variable named by StaticPasswordHashEnv (mirroring dex's
+// hashFromEnv config extension) over the inline static_password_hash value.
+func resolveStaticPasswordHash(cfg *Config) (string, error) {
+	if cfg.StaticPasswordHashEnv != "" {
+		hash := os.Getenv(cfg.StaticPasswordHashEnv)
+		if hash == "" {
+			return "", fmt.Errorf("static_password_hash_env is set to '%s' but that variable is empty or unset", cfg.StaticPasswordHashEnv)
+		}
+		return hash, nil
+	}
+	if cfg.StaticPasswordHash != "" {
+		return cfg.StaticPasswordHash, nil
+	}
+	return "", fmt.Errorf("no static password hash configured - set static_password_hash or static_password_hash_env")
+}
+
+// resolveStaticPassword returns the plaintext password to verify, preferring
+// the environment variable named by StaticPasswordEnv (default TOM_PASSWORD)
+// and falling back to an interactive terminal prompt so the password never
+// has to touch disk or a shell history.
+func resolveStaticPassword(cfg *Config) (string, error) {
+	envVar := cfg.StaticPasswordEnv
+	if envVar == "" {
+		envVar = "TOM_PASSWORD"
+	}
+	if password := os.Getenv(envVar); password != "" {
+		return password, nil
+	}
+	return PromptSecret("Password: ")
+}
+
+// AuthenticateStaticPassword verifies the configured password against its
+// bcrypt hash and, on success, exchanges the credentials for a session token
+// against the Tom API's /api/token endpoint. It gives operators a way to run
+// tomclient in CI or container environments without an OAuth browser or a
+// long-lived API key checked into config.
+func AuthenticateStaticPassword(cfg *Config) error {
+	hash, err := resolveStaticPasswordHash(cfg)
+	if err != nil {
+		return err
+	}
+
+	password, err := resolveStaticPassword(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return fmt.Errorf("password does not match configured hash")
+	}
+
+	token, err := exchangeStaticPasswordToken(cfg, password)
+	if err != nil {
+		return fmt.Errorf("failed to exchange credentials for a session token: %w", err)
+	}
+
+	if err := SaveToken(token, cfg.ConfigDir, "static_password"); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+
+	return nil
+}
+
+func exchangeStaticPasswordToken(cfg *Config, password string) (*TokenResponse, error) {
+	tokenURL := fmt.Sprintf("%s/api/token", cfg.APIURL)
+
+	data := url.Values{
+		"grant_type": {"password"},
+		"username":   {cfg.StaticPasswordUsername},
+		"password":   {password},
+	}
+
+	resp, err := http.PostForm(tokenURL, data)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("token exchange failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var token TokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+
+	return &token, nil
+}
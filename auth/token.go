@@ -87,6 +87,27 @@ func SaveToken(token *TokenResponse, configDir string, provider string) error {
 		RefreshExpiresAt: refreshExpiresAt,
 	}
 
+	backend := GetSecretBackend(configDir)
+	if backend != "file" {
+		store := NewSecretStore(backend, configDir)
+		if err := store.Set("access_token", stored.AccessToken); err != nil {
+			return fmt.Errorf("failed to store access token: %w", err)
+		}
+		if err := store.Set("id_token", stored.IDToken); err != nil {
+			return fmt.Errorf("failed to store id token: %w", err)
+		}
+		if stored.RefreshToken != "" {
+			if err := store.Set("refresh_token", stored.RefreshToken); err != nil {
+				return fmt.Errorf("failed to store refresh token: %w", err)
+			}
+		}
+		// Non-secret metadata still lives on disk; the actual token values
+		// are held by the secret store and re-hydrated on load.
+		stored.AccessToken = ""
+		stored.IDToken = ""
+		stored.RefreshToken = ""
+	}
+
 	data, err := json.MarshalIndent(stored, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal token: %w", err)
@@ -116,21 +137,43 @@ func LoadToken(configDir string) (*StoredToken, error) {
 		return nil, fmt.Errorf("failed to parse token file: %w", err)
 	}
 
+	backend := GetSecretBackend(configDir)
+	if backend != "file" {
+		store := NewSecretStore(backend, configDir)
+		if v, ok, err := store.Get("access_token"); err == nil && ok {
+			token.AccessToken = v
+		}
+		if v, ok, err := store.Get("id_token"); err == nil && ok {
+			token.IDToken = v
+		}
+		if v, ok, err := store.Get("refresh_token"); err == nil && ok {
+			token.RefreshToken = v
+		}
+	}
+
 	return &token, nil
 }
 
-// IsValid returns whether the currently used token is valid.
-// For OIDC we use the ID token; validate by its exp when present.
-func (t *StoredToken) IsValid() bool {
+// ValidFor reports whether the token is still valid at least leeway from
+// now. IsValid uses a small fixed leeway to answer "is this usable right
+// now"; callers deciding whether to refresh proactively (before the token
+// actually expires) pass a larger leeway instead.
+func (t *StoredToken) ValidFor(leeway time.Duration) bool {
 	if t.IDToken != "" {
 		if exp, ok := parseJWTExp(t.IDToken); ok {
-			return time.Now().Before(exp.Add(-60 * time.Second))
+			return time.Now().Before(exp.Add(-leeway))
 		}
 		// If we cannot parse, be conservative and treat as expired
 		return false
 	}
 	// Fallback: access token expiry
-	return time.Now().Before(t.ExpiresAt.Add(-60 * time.Second))
+	return time.Now().Before(t.ExpiresAt.Add(-leeway))
+}
+
+// IsValid returns whether the currently used token is valid.
+// For OIDC we use the ID token; validate by its exp when present.
+func (t *StoredToken) IsValid() bool {
+	return t.ValidFor(60 * time.Second)
 }
 
 // GetToken returns the ID token; we no longer fall back to access tokens.
@@ -161,6 +204,16 @@ func parseJWTExp(jwt string) (time.Time, bool) {
 }
 
 func DeleteToken(configDir string) error {
+	backend := GetSecretBackend(configDir)
+	if backend != "file" {
+		store := NewSecretStore(backend, configDir)
+		for _, key := range []string{"access_token", "id_token", "refresh_token"} {
+			if err := store.Delete(key); err != nil {
+				return fmt.Errorf("failed to delete %s from secret store: %w", key, err)
+			}
+		}
+	}
+
 	tokenPath := GetTokenPath(configDir)
 	if err := os.Remove(tokenPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete token file: %w", err)
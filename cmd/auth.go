@@ -2,24 +2,121 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"tomclient/auth"
+	"tomclient/output"
 
 	"github.com/spf13/cobra"
 )
 
+// authStatus is the structured form of 'auth status', used when --output
+// selects json/yaml/csv instead of the default human-readable text.
+type authStatus struct {
+	AuthMode      string `json:"auth_mode" yaml:"auth_mode"`
+	ConfigDir     string `json:"config_dir" yaml:"config_dir"`
+	Authenticated bool   `json:"authenticated" yaml:"authenticated"`
+	Detail        string `json:"detail" yaml:"detail"`
+}
+
+var authStatusColumns = []output.Column{
+	{Header: "AUTH_MODE", Value: func(r interface{}) string { return r.(authStatus).AuthMode }},
+	{Header: "AUTHENTICATED", Value: func(r interface{}) string { return fmt.Sprintf("%v", r.(authStatus).Authenticated) }},
+	{Header: "DETAIL", Value: func(r interface{}) string { return r.(authStatus).Detail }},
+}
+
+// buildAuthStatus computes the same status 'auth status' prints as text by
+// default, but as structured data for --output json/yaml/csv.
+func buildAuthStatus(cfg *auth.Config) authStatus {
+	status := authStatus{AuthMode: string(cfg.AuthMode), ConfigDir: cfg.ConfigDir}
+
+	switch cfg.AuthMode {
+	case auth.AuthModeNone:
+		status.Detail = "no authentication configured"
+
+	case auth.AuthModeAPIKey:
+		status.Authenticated = cfg.APIKey != ""
+		if status.Authenticated {
+			status.Detail = "API key configured"
+		} else {
+			status.Detail = "API key not set (TOM_API_KEY required)"
+		}
+
+	case auth.AuthModeJWT:
+		if cfg.OAuthClientID == "" || cfg.OAuthDiscoveryURL == "" {
+			status.Detail = "OAuth configuration incomplete (TOM_OAUTH_CLIENT_ID / TOM_OAUTH_DISCOVERY_URL required)"
+			return status
+		}
+
+		token, err := auth.LoadToken(cfg.ConfigDir)
+		if err != nil {
+			status.Detail = "not authenticated - run 'tomclient auth login'"
+			return status
+		}
+
+		if token.IsValid() {
+			status.Authenticated = true
+			status.Detail = fmt.Sprintf("expires in %v", time.Until(token.ExpiresAt).Round(time.Second))
+		} else {
+			status.Detail = "token expired - run 'tomclient auth login'"
+		}
+
+	case auth.AuthModeStaticPassword:
+		if cfg.StaticPasswordUsername == "" {
+			status.Detail = "static_password_username not set"
+			return status
+		}
+
+		token, err := auth.LoadToken(cfg.ConfigDir)
+		if err != nil {
+			status.Detail = "not authenticated - run 'tomclient auth login'"
+			return status
+		}
+
+		if token.AccessToken != "" && time.Now().Before(token.ExpiresAt) {
+			status.Authenticated = true
+			status.Detail = fmt.Sprintf("expires in %v", time.Until(token.ExpiresAt).Round(time.Second))
+		} else {
+			status.Detail = "token expired - run 'tomclient auth login'"
+		}
+	}
+
+	return status
+}
+
 var authCmd = &cobra.Command{
 	Use:   "auth",
 	Short: "Manage authentication",
 	Long:  `Manage authentication for the Tom API (OAuth, API keys, etc.)`,
 }
 
+var (
+	authLoginDevice bool
+	authLoginManual bool
+)
+
 var authLoginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with OAuth",
 	Long: `Authenticate with the Tom API using OAuth/PKCE flow.
-Opens a browser window for authentication with your identity provider.`,
+Opens a browser window for authentication with your identity provider.
+
+Use --device on headless hosts (jump boxes, SSH sessions, CI runners) where
+no local browser can reach a loopback callback; this uses the RFC 8628
+device authorization grant instead. The browser flow also falls back to
+this automatically when it can't launch a browser at all.
+
+Use --manual when even the loopback callback itself is unreachable (the
+redirect port is firewalled, already bound, or the browser is on a
+different machine entirely, e.g. local browser + remote SSH session): the
+provider is asked to display the authorization code on its own page
+instead of redirecting back, and you paste it into the terminal.
+
+With auth_mode='static_password', no browser or device code is involved at
+all: the configured password (TOM_PASSWORD by default) is checked against
+its configured bcrypt hash and exchanged for a session token, which is the
+right fit for CI and container environments that can't do either OAuth flow.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 		cfg, err := auth.LoadConfig(configDir)
@@ -31,8 +128,24 @@ Opens a browser window for authentication with your identity provider.`,
 			return err
 		}
 
+		if cfg.AuthMode == auth.AuthModeStaticPassword {
+			if err := auth.AuthenticateStaticPassword(cfg); err != nil {
+				return err
+			}
+			fmt.Println("✅ Authentication successful!")
+			return nil
+		}
+
 		if cfg.AuthMode != auth.AuthModeJWT {
-			return fmt.Errorf("auth mode is '%s' but 'auth login' requires auth_mode='jwt'\nSet TOM_AUTH_MODE=jwt or update your config file", cfg.AuthMode)
+			return fmt.Errorf("auth mode is '%s' but 'auth login' requires auth_mode='jwt' or 'static_password'\nSet TOM_AUTH_MODE=jwt or update your config file", cfg.AuthMode)
+		}
+
+		if authLoginDevice {
+			return auth.AuthenticateDevice(cfg)
+		}
+
+		if authLoginManual {
+			cfg.OAuthManualRedirect = true
 		}
 
 		return auth.Authenticate(cfg)
@@ -50,6 +163,11 @@ var authStatusCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		if format := getOutputFormat(); format != output.FormatTable {
+			status := buildAuthStatus(cfg)
+			return output.Render(os.Stdout, format, status, authStatusColumns, []interface{}{status})
+		}
+
 		fmt.Printf("Auth Mode: %s\n", cfg.AuthMode)
 		fmt.Printf("Config Dir: %s\n", cfg.ConfigDir)
 
@@ -99,6 +217,25 @@ var authStatusCmd = &cobra.Command{
 			} else {
 				fmt.Println("Refresh Token: present (expired)")
 			}
+
+		case auth.AuthModeStaticPassword:
+			if cfg.StaticPasswordUsername == "" {
+				fmt.Println("Status: ❌ static_password_username not set")
+				return nil
+			}
+			fmt.Printf("Static Password Username: %s\n", cfg.StaticPasswordUsername)
+
+			token, err := auth.LoadToken(cfg.ConfigDir)
+			if err != nil {
+				fmt.Println("Status: ❌ Not authenticated - run 'tomclient auth login'")
+				return nil
+			}
+
+			if token.AccessToken != "" && time.Now().Before(token.ExpiresAt) {
+				fmt.Printf("Status: ✅ Authenticated (expires in %v)\n", time.Until(token.ExpiresAt).Round(time.Second))
+			} else {
+				fmt.Println("Status: ❌ Token expired - run 'tomclient auth login'")
+			}
 		}
 
 		return nil
@@ -140,7 +277,6 @@ var authRecordCmd = &cobra.Command{
 			return err
 		}
 
-		client := createClient(getAPIURL(cfg), cfg)
 		if err := client.RecordJWT(); err != nil {
 			return fmt.Errorf("failed to record JWT: %w", err)
 		}
@@ -156,4 +292,7 @@ func init() {
 	authCmd.AddCommand(authStatusCmd)
 	authCmd.AddCommand(authLogoutCmd)
 	authCmd.AddCommand(authRecordCmd)
+
+	authLoginCmd.Flags().BoolVar(&authLoginDevice, "device", false, "Use the device authorization grant instead of opening a browser")
+	authLoginCmd.Flags().BoolVar(&authLoginManual, "manual", false, "Use an out-of-band redirect and paste the authorization code manually, instead of a loopback callback")
 }
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"tomclient/internal"
+)
+
+var (
+	backupOutputDir   string
+	backupFormat      string
+	backupDiffAgainst string
+	backupParallel    int
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Archive running-config from every selected device to disk",
+	Long: `Pull 'show running-config' from every selected device (--devices, --match,
+or --filter, the same device selection as 'grep') and write each as
+<output-dir>/<device>.cfg, alongside a manifest.json recording each file's
+timestamp, sha256, and byte length.
+
+Use --format to choose between a plain directory of files (the default) or
+a single tar/tar.gz archive; for tar/tar.gz, --output-dir names the archive
+file rather than a directory.
+
+Use --diff-against <previous-backup-dir> to compare this run against an
+earlier plain-format backup: a unified diff is printed for every changed
+device, followed by a summary of devices added, removed, and changed.`,
+	Example: `  tomclient backup --match "^SCCSNJ" --output-dir backups/2026-07-27
+  tomclient backup --devices router1,router2 --output-dir backups/today.tar.gz --format tar.gz
+  tomclient backup --match "^SCCSNJ" --output-dir backups/today --diff-against backups/yesterday`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if backupFormat != "plain" && backupFormat != "tar" && backupFormat != "tar.gz" {
+			return fmt.Errorf("invalid --format value: %s (must be one of: plain, tar, tar.gz)", backupFormat)
+		}
+		if backupDiffAgainst != "" && backupFormat != "plain" {
+			return fmt.Errorf("--diff-against requires --format plain")
+		}
+
+		devices, err := resolveDevices()
+		if err != nil {
+			return err
+		}
+		if len(devices) == 0 {
+			return fmt.Errorf("no devices specified; use --devices, --match, or --filter")
+		}
+
+		configs, errs := internal.FetchDeviceConfigs(client, devices, backupParallel)
+		for device, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s: error: %v\n", device, err)
+		}
+		if len(configs) == 0 {
+			return fmt.Errorf("no device configs were fetched successfully")
+		}
+
+		manifest, err := internal.WriteBackup(backupOutputDir, backupFormat, configs)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up %d device(s) to %s\n", len(manifest), backupOutputDir)
+
+		if backupDiffAgainst != "" {
+			fetched := make([]string, 0, len(configs))
+			for device := range configs {
+				fetched = append(fetched, device)
+			}
+
+			summary, err := internal.DiffBackups(backupDiffAgainst, backupOutputDir, fetched, configs)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n%d changed, %d added, %d removed (vs %s)\n",
+				len(summary.Changed), len(summary.Added), len(summary.Removed), backupDiffAgainst)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+
+	// Device selection, shared with 'grep' via the same package-level flags.
+	backupCmd.Flags().StringVarP(&grepDevices, "devices", "D", "", "Comma-separated list of device names")
+	backupCmd.Flags().StringVarP(&grepMatch, "match", "m", "", "Regex pattern to match device names")
+	backupCmd.Flags().StringVarP(&grepFilter, "filter", "f", "", "Use named inventory filter")
+
+	backupCmd.Flags().StringVar(&backupOutputDir, "output-dir", "", "Output directory (plain) or archive file path (tar/tar.gz)")
+	backupCmd.Flags().StringVar(&backupFormat, "format", "plain", "Output format: plain, tar, tar.gz")
+	backupCmd.Flags().StringVar(&backupDiffAgainst, "diff-against", "", "Compare against a previous plain-format backup directory")
+	backupCmd.Flags().IntVarP(&backupParallel, "parallel", "c", 10, "Number of concurrent device queries")
+
+	backupCmd.MarkFlagRequired("output-dir")
+}
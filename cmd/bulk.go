@@ -1,15 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	
+	"os"
+	"os/signal"
+	"time"
+
 	"github.com/spf13/cobra"
 	"tomclient/internal"
 )
 
 var (
-	bulkConcurrency int
-	bulkOutputDir   string
+	bulkConcurrency  int
+	bulkOutputDir    string
+	bulkRetryTimeout time.Duration
+	bulkRetrySleep   time.Duration
+	bulkMaxAttempts  int
+	bulkTextfileDir  string
 )
 
 var bulkInventoryCmd = &cobra.Command{
@@ -24,7 +32,17 @@ Supports concurrent execution with configurable worker count.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		devicesFile := args[0]
 
-		err := internal.BulkInventory(client, devicesFile, bulkConcurrency)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		retry := internal.RetryOptions{
+			MaxAttempts:  bulkMaxAttempts,
+			Interval:     bulkRetrySleep,
+			RetryTimeout: bulkRetryTimeout,
+			Jitter:       500 * time.Millisecond,
+		}
+
+		err := internal.BulkInventory(ctx, client, devicesFile, bulkConcurrency, retry, bulkTextfileDir)
 		handleError(err)
 	},
 }
@@ -36,6 +54,15 @@ func init() {
 	bulkInventoryCmd.Flags().IntVarP(&bulkConcurrency, "concurrency", "c", 20, "Number of concurrent workers")
 	bulkInventoryCmd.Flags().StringVarP(&bulkOutputDir, "output-dir", "o", "inventory", "Output directory for inventory files")
 
+	// Retry on transient errors (network issues, 502/503/504); each worker
+	// retries its own device independently, so a flaky one doesn't stall others.
+	// Ctrl-C cancels the whole batch instead of waiting out in-flight retries.
+	bulkInventoryCmd.Flags().DurationVar(&bulkRetryTimeout, "retry-timeout", 2*time.Minute, "Keep retrying a device on transient errors until this duration elapses")
+	bulkInventoryCmd.Flags().DurationVar(&bulkRetrySleep, "retry-sleep", 2*time.Second, "Initial sleep between retries (doubles up to a cap)")
+	bulkInventoryCmd.Flags().IntVar(&bulkMaxAttempts, "max-attempts", 5, "Give up on a device after this many attempts, even if retry-timeout hasn't elapsed")
+
+	bulkInventoryCmd.Flags().StringVar(&bulkTextfileDir, "textfile-dir", "", "Write a tom_collection_errors_total node_exporter textfile (tom_bulk_inventory.prom) into this directory")
+
 	// Validation
 	bulkInventoryCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		if bulkConcurrency < 1 {
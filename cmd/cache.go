@@ -1,32 +1,110 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"tomclient/auth"
+	"tomclient/tomapi"
 )
 
 var (
 	cacheDevice string
 	cacheAll    bool
+	cacheLocal  bool
+
+	cacheWarmCommands    string
+	cacheWarmConcurrency int
+	cacheWarmDelay       time.Duration
+	cacheWarmTTL         int
+
+	cacheRefreshPattern  string
+	cacheRefreshInterval time.Duration
+	cacheRefreshDaemon   bool
+
+	cacheRetryTimeout time.Duration
+	cacheRetrySleep   time.Duration
+	cacheMaxAttempts  int
 )
 
+// retryTimeoutExitCode is returned instead of the usual exit code 1 when a
+// cache command gives up because its RetryPolicy ran out of attempts or
+// time, so a startup-orchestration script ("poll 'cache stats' until the
+// server's ready or 60s elapses") can tell "gave up waiting" apart from
+// every other failure.
+const retryTimeoutExitCode = 3
+
+// applyCacheRetryPolicy wires --retry-timeout/--sleep/--max-attempts onto
+// the shared client, so every cache subcommand's requests transparently
+// retry transient failures. It's a no-op, leaving client.RetryPolicy nil,
+// unless --retry-timeout was set - preserving the single-attempt default
+// everywhere else.
+func applyCacheRetryPolicy() {
+	if cacheRetryTimeout <= 0 {
+		return
+	}
+	client.RetryPolicy = &tomapi.RetryPolicy{
+		MaxAttempts:  cacheMaxAttempts,
+		RetryTimeout: cacheRetryTimeout,
+		Backoff:      cacheRetrySleep,
+	}
+}
+
+// handleCacheError prints err and exits like handleError, except a
+// RetryTimeoutError exits with retryTimeoutExitCode instead of 1.
+func handleCacheError(err error) {
+	if err == nil {
+		return
+	}
+	var rte *tomapi.RetryTimeoutError
+	if errors.As(err, &rte) {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(retryTimeoutExitCode)
+	}
+	handleError(err)
+}
+
 var cacheCmd = &cobra.Command{
 	Use:   "cache",
 	Short: "Manage command output cache",
 	Long: `Manage the Tom API cache for device command outputs.
-	
+
 Cache entries store device command results to reduce load on network devices
-and improve response times for frequently used commands.`,
+and improve response times for frequently used commands.
+
+'list' and 'clear' also accept --local, redirecting them to the local
+on-disk command cache (~/.tom/cmd_cache) populated by 'tomclient
+device' and 'tomclient grep' instead of the server's cache; 'prune' only
+ever affects that local cache.
+
+'warm' and 'refresh' work the other direction: 'warm' pre-populates the
+server's cache for a device/command matrix ahead of a bulk report, and
+'refresh' re-fetches entries before they expire, either as a single pass or
+continuously with --daemon.
+
+--retry-timeout/--sleep/--max-attempts (shared by every subcommand here)
+make the underlying request retry transient errors (network issues,
+502/503/504) until it succeeds or --retry-timeout elapses, useful for
+startup orchestration like "wait for 'cache stats' to respond before
+proceeding." A give-up due to --retry-timeout exits with a distinct code
+(3) instead of the usual 1, so scripts can tell "gave up waiting" apart
+from other failures.`,
 	Example: `  tomclient cache stats
   tomclient cache list
   tomclient cache list --device router1
+  tomclient cache list --local
   tomclient cache invalidate router1
-  tomclient cache clear --all`,
+  tomclient cache clear --all
+  tomclient cache prune
+  tomclient cache warm --devices router1,router2 --commands "show version,show ip route"
+  tomclient cache refresh --daemon --interval 2m`,
 }
 
 var cacheStatsCmd = &cobra.Command{
@@ -36,9 +114,10 @@ var cacheStatsCmd = &cobra.Command{
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.SilenceUsage = true
+		applyCacheRetryPolicy()
 
 		stats, err := client.GetCacheStats()
-		handleError(err)
+		handleCacheError(err)
 
 		fmt.Printf("Cache Statistics\n")
 		fmt.Printf("================\n")
@@ -63,13 +142,39 @@ var cacheStatsCmd = &cobra.Command{
 var cacheListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List cache keys",
-	Long:  `List all cache keys, optionally filtered by device name.`,
-	Args:  cobra.NoArgs,
+	Long: `List all cache keys, optionally filtered by device name.
+
+--local lists the local on-disk command cache (~/.tom/cmd_cache)
+instead of the Tom API server's cache.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.SilenceUsage = true
 
+		if cacheLocal {
+			cfg, err := auth.LoadConfig(configDir)
+			handleError(err)
+
+			entries, err := auth.ListCommandCache(cfg.ConfigDir, cacheDevice)
+			handleError(err)
+
+			fmt.Printf("Local Command Cache\n")
+			fmt.Printf("Count: %d\n", len(entries))
+
+			if len(entries) > 0 {
+				fmt.Printf("\nEntries:\n")
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintf(w, "  DEVICE\tCACHED AT\tEXPIRES AT\tEXPIRED\n")
+				for _, e := range entries {
+					fmt.Fprintf(w, "  %s\t%s\t%s\t%v\n", e.Device, e.CachedAt.Format("2006-01-02 15:04:05"), e.ExpiresAt.Format("2006-01-02 15:04:05"), e.Expired)
+				}
+				w.Flush()
+			}
+			return
+		}
+
+		applyCacheRetryPolicy()
 		keys, err := client.ListCacheKeys(cacheDevice)
-		handleError(err)
+		handleCacheError(err)
 
 		if keys.DeviceFilter != nil && *keys.DeviceFilter != "" {
 			fmt.Printf("Cache Keys for Device: %s\n", *keys.DeviceFilter)
@@ -118,10 +223,11 @@ var cacheInvalidateCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.SilenceUsage = true
+		applyCacheRetryPolicy()
 
 		deviceName := args[0]
 		result, err := client.InvalidateDeviceCache(deviceName)
-		handleError(err)
+		handleCacheError(err)
 
 		fmt.Println(result.Message)
 	},
@@ -131,7 +237,10 @@ var cacheClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear cache entries",
 	Long: `Clear all cache entries across all devices.
-Requires --all flag to confirm clearing all cache.`,
+Requires --all flag to confirm clearing all cache.
+
+--local clears the local on-disk command cache (~/.tom/cmd_cache)
+instead of the Tom API server's cache.`,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.SilenceUsage = true
@@ -142,13 +251,133 @@ Requires --all flag to confirm clearing all cache.`,
 			os.Exit(1)
 		}
 
+		if cacheLocal {
+			cfg, err := auth.LoadConfig(configDir)
+			handleError(err)
+
+			removed, err := auth.ClearCommandCache(cfg.ConfigDir, cacheDevice)
+			handleError(err)
+
+			fmt.Printf("Removed %d local cache entries\n", removed)
+			return
+		}
+
+		applyCacheRetryPolicy()
 		result, err := client.ClearAllCache()
-		handleError(err)
+		handleCacheError(err)
 
 		fmt.Println(result.Message)
 	},
 }
 
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired entries from the local command cache",
+	Long: `Remove every entry in the local on-disk command cache
+(~/.tom/cmd_cache) whose TTL has already expired.
+
+This only affects the local cache populated by 'tomclient device' and
+'tomclient grep'; it has no effect on the Tom API server's own cache.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+
+		cfg, err := auth.LoadConfig(configDir)
+		handleError(err)
+
+		removed, err := auth.PruneExpiredCommandCache(cfg.ConfigDir)
+		handleError(err)
+
+		fmt.Printf("Removed %d expired local cache entries\n", removed)
+	},
+}
+
+var cacheWarmCmd = &cobra.Command{
+	Use:   "warm",
+	Short: "Pre-populate the server cache for a device/command matrix",
+	Long: `Fire a bounded-concurrency, cache-refreshing request against every
+(device, command) pair for the selected devices (--devices, --match, or
+--filter, the same device selection as 'grep') and --commands, so a
+subsequent bulk report never pays a cold-cache penalty.
+
+The requested TTL is clamped to the server's max_ttl (see 'cache stats').`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		applyCacheRetryPolicy()
+
+		if cacheWarmCommands == "" {
+			return fmt.Errorf("--commands is required")
+		}
+		commands := strings.Split(cacheWarmCommands, ",")
+
+		devices, err := resolveDevices()
+		if err != nil {
+			return err
+		}
+		if len(devices) == 0 {
+			return fmt.Errorf("no devices specified; use --devices, --match, or --filter")
+		}
+
+		results, err := client.WarmCache(devices, commands, tomapi.WarmOptions{
+			Concurrency:    cacheWarmConcurrency,
+			PerDeviceDelay: cacheWarmDelay,
+			TTL:            cacheWarmTTL,
+		})
+		if err != nil {
+			return err
+		}
+
+		var failed int
+		for _, r := range results {
+			if r.Error != nil {
+				failed++
+				fmt.Fprintf(os.Stderr, "%s: %q: %v\n", r.Device, r.Command, r.Error)
+			}
+		}
+		fmt.Printf("Warmed %d/%d (device, command) pair(s)\n", len(results)-failed, len(results))
+		return nil
+	},
+}
+
+var cacheRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Re-fetch cache entries before they expire",
+	Long: `Poll the server's cache keys (optionally filtered by --device, same as
+'cache list') on --interval and re-fetch each entry on the first pass that
+lists it, then again once 90% of the server's default_ttl has passed since
+its last refresh, so a long-running job never observes a key expiring mid-run.
+
+Without --daemon, this runs a single poll-and-refresh pass, which re-fetches
+every listed entry, and exits; with
+--daemon, it keeps running until interrupted (Ctrl-C).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		applyCacheRetryPolicy()
+
+		if !cacheRefreshDaemon {
+			if err := client.RefreshStaleCache(cacheRefreshPattern); err != nil {
+				var rte *tomapi.RetryTimeoutError
+				if errors.As(err, &rte) {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(retryTimeoutExitCode)
+				}
+				return err
+			}
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if err := client.WatchAndRefresh(ctx, cacheRefreshPattern, cacheRefreshInterval); err != nil && err != context.Canceled {
+			return err
+		}
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(cacheCmd)
 
@@ -157,11 +386,38 @@ func init() {
 	cacheCmd.AddCommand(cacheListCmd)
 	cacheCmd.AddCommand(cacheInvalidateCmd)
 	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheWarmCmd)
+	cacheCmd.AddCommand(cacheRefreshCmd)
 
 	// Add flags
 	cacheListCmd.Flags().StringVarP(&cacheDevice, "device", "d", "", "Filter by device name")
+	cacheListCmd.Flags().BoolVar(&cacheLocal, "local", false, "List the local on-disk command cache instead of the server's")
 	cacheClearCmd.Flags().BoolVar(&cacheAll, "all", false, "Confirm clearing all cache entries")
+	cacheClearCmd.Flags().BoolVar(&cacheLocal, "local", false, "Clear the local on-disk command cache instead of the server's")
 
 	// Mark --all as required for clear command
 	cacheClearCmd.MarkFlagRequired("all")
+
+	// Retry/backoff, shared by every cache subcommand that talks to the
+	// server (see applyCacheRetryPolicy). --retry-timeout is the on/off
+	// switch: 0 leaves client.RetryPolicy nil, i.e. today's single-attempt
+	// behavior.
+	cacheCmd.PersistentFlags().DurationVar(&cacheRetryTimeout, "retry-timeout", 0, "Keep retrying cache requests on transient errors until this duration elapses (0 disables retries)")
+	cacheCmd.PersistentFlags().DurationVar(&cacheRetrySleep, "sleep", time.Second, "Initial sleep between retries (doubles up to a cap)")
+	cacheCmd.PersistentFlags().IntVar(&cacheMaxAttempts, "max-attempts", 5, "Maximum attempts before giving up")
+
+	// Device selection, shared with 'grep'/'backup' via the same package-level flags.
+	cacheWarmCmd.Flags().StringVarP(&grepDevices, "devices", "D", "", "Comma-separated list of device names")
+	cacheWarmCmd.Flags().StringVarP(&grepMatch, "match", "m", "", "Regex pattern to match device names")
+	cacheWarmCmd.Flags().StringVarP(&grepFilter, "filter", "f", "", "Use named inventory filter")
+	cacheWarmCmd.Flags().StringVar(&cacheWarmCommands, "commands", "", "Comma-separated list of commands to warm")
+	cacheWarmCmd.Flags().IntVarP(&cacheWarmConcurrency, "parallel", "c", 5, "Number of devices warmed concurrently")
+	cacheWarmCmd.Flags().DurationVar(&cacheWarmDelay, "device-delay", 0, "Minimum delay between requests to the same device")
+	cacheWarmCmd.Flags().IntVar(&cacheWarmTTL, "ttl", 0, "Requested cache TTL in seconds (clamped to the server's max_ttl); 0 uses the server's max_ttl")
+	cacheWarmCmd.MarkFlagRequired("commands")
+
+	cacheRefreshCmd.Flags().StringVarP(&cacheRefreshPattern, "device", "d", "", "Filter by device name")
+	cacheRefreshCmd.Flags().DurationVar(&cacheRefreshInterval, "interval", 1*time.Minute, "Poll interval between refresh passes (--daemon only)")
+	cacheRefreshCmd.Flags().BoolVar(&cacheRefreshDaemon, "daemon", false, "Keep polling and refreshing until interrupted (Ctrl-C)")
 }
@@ -1,14 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
+	"tomclient/internal"
+	"tomclient/tomapi"
 )
 
 var (
@@ -17,6 +21,10 @@ var (
 	interfacesConcurrency   int
 	interfacesFilterRouters bool
 	interfacesCommand       string
+
+	interfacesRetryTimeout time.Duration
+	interfacesSleep        time.Duration
+	interfacesMaxAttempts  int
 )
 
 var collectInterfacesCmd = &cobra.Command{
@@ -60,24 +68,37 @@ Supports filtering to routers only and concurrent execution.`,
 		err = os.MkdirAll(interfacesOutputDir, 0755)
 		handleError(err)
 
-		fmt.Printf("Collecting interface configs from %d devices with %d concurrent workers...\n", 
+		fmt.Printf("Collecting interface configs from %d devices with %d concurrent workers...\n",
 			len(targetDevices), interfacesConcurrency)
 
-		// Execute concurrent collection
-		sem := make(chan struct{}, interfacesConcurrency)
-		var wg sync.WaitGroup
-
-		for _, hostname := range targetDevices {
-			wg.Add(1)
-			go func(h string) {
-				sem <- struct{}{}
-				collectDeviceInterfaces(h, &wg)
-				<-sem
-			}(hostname)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		retry := internal.RetryOptions{
+			MaxAttempts:  interfacesMaxAttempts,
+			Interval:     interfacesSleep,
+			RetryTimeout: interfacesRetryTimeout,
+			Jitter:       500 * time.Millisecond,
+			RetryOn:      tomapi.IsRetryableError,
+		}
+
+		results := internal.RunConcurrent(ctx, targetDevices, interfacesConcurrency, retry, func(ctx context.Context, hostname string) error {
+			return collectDeviceInterfaces(hostname)
+		})
+
+		var retried, failed int
+		for _, r := range results {
+			switch {
+			case r.Err != nil:
+				failed++
+				fmt.Printf("Error collecting interfaces from %s (after %d attempts): %v\n", r.Item, r.Attempts, r.Err)
+			case r.Attempts > 1:
+				retried++
+				fmt.Printf("Collected interfaces from %s after %d attempts\n", r.Item, r.Attempts)
+			}
 		}
 
-		wg.Wait()
-		fmt.Println("Interface config collection completed.")
+		fmt.Printf("Interface config collection completed (%d retried, %d failed).\n", retried, failed)
 	},
 }
 
@@ -106,25 +127,24 @@ func isRouter(hostname string) bool {
 	return true // Default to true for unknown patterns
 }
 
-func collectDeviceInterfaces(hostname string, wg *sync.WaitGroup) {
-	defer wg.Done()
-
+// collectDeviceInterfaces fetches and saves one device's interface config.
+// It's retried by RunConcurrent on a transient error, so it returns the
+// error instead of just logging it.
+func collectDeviceInterfaces(hostname string) error {
 	fmt.Printf("Collecting interfaces from %s...\n", hostname)
 
-	result, err := client.SendDeviceCommand(hostname, interfacesCommand, true, true)
+	result, err := client.SendDeviceCommand(hostname, interfacesCommand, true, true, false, nil, false)
 	if err != nil {
-		fmt.Printf("Error collecting interfaces from %s: %v\n", hostname, err)
-		return
+		return err
 	}
 
 	filename := filepath.Join(interfacesOutputDir, hostname+"_interfaces.txt")
-	err = os.WriteFile(filename, []byte(result), 0644)
-	if err != nil {
-		fmt.Printf("Error writing file for %s: %v\n", hostname, err)
-		return
+	if err := os.WriteFile(filename, []byte(result), 0644); err != nil {
+		return fmt.Errorf("error writing file for %s: %w", hostname, err)
 	}
 
 	fmt.Printf("Saved interface config for %s to %s\n", hostname, filename)
+	return nil
 }
 
 func init() {
@@ -136,6 +156,14 @@ func init() {
 	collectInterfacesCmd.Flags().BoolVarP(&interfacesFilterRouters, "routers-only", "r", true, "Only collect from routers (filter out switches)")
 	collectInterfacesCmd.Flags().StringVar(&interfacesCommand, "command", "show running-config | section interface", "Command to collect interface configs")
 
+	// Retry on transient errors (network issues, 502/503/504); each worker
+	// retries its own device independently, so a flaky one doesn't stall
+	// others. Ctrl-C cancels the whole batch instead of waiting out in-flight
+	// retries.
+	collectInterfacesCmd.Flags().DurationVar(&interfacesRetryTimeout, "retry-timeout", 2*time.Minute, "Keep retrying a device on transient errors until this duration elapses")
+	collectInterfacesCmd.Flags().DurationVar(&interfacesSleep, "sleep", 2*time.Second, "Initial sleep between retries (doubles up to a cap)")
+	collectInterfacesCmd.Flags().IntVar(&interfacesMaxAttempts, "max-attempts", 5, "Give up on a device after this many attempts, even if retry-timeout hasn't elapsed")
+
 	// Validation
 	collectInterfacesCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		if interfacesConcurrency < 1 {
@@ -175,12 +175,167 @@ Examples:
 	},
 }
 
+var configDeleteCmd = &cobra.Command{
+	Use:   "delete <profile-name>",
+	Short: "Delete a configuration profile",
+	Long:  `Delete a configuration profile. If it is the currently active profile, config.json's active profile is cleared.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profileName := args[0]
+
+		if err := auth.DeleteProfile(configDir, profileName); err != nil {
+			return err
+		}
+
+		fmt.Printf("Profile '%s' deleted\n", profileName)
+		return nil
+	},
+}
+
+var configRenameCmd = &cobra.Command{
+	Use:   "rename <old-name> <new-name>",
+	Short: "Rename a configuration profile",
+	Long:  `Rename a configuration profile, updating config.json's active profile if it was the renamed one.`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldName, newName := args[0], args[1]
+
+		if err := auth.RenameProfile(configDir, oldName, newName); err != nil {
+			return err
+		}
+
+		fmt.Printf("Profile '%s' renamed to '%s'\n", oldName, newName)
+		return nil
+	},
+}
+
+var configCurrentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the currently active profile name",
+	Long:  `Print the name of the profile config.json currently includes, if any.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		currentProfile, err := auth.GetCurrentProfile(configDir)
+		if err != nil {
+			return fmt.Errorf("failed to get current profile: %w", err)
+		}
+
+		if currentProfile == "" {
+			fmt.Println("No profile currently active.")
+			return nil
+		}
+
+		fmt.Println(currentProfile)
+		return nil
+	},
+}
+
+var configSetSecretBackendCmd = &cobra.Command{
+	Use:   "set-secret-backend {file|keyring|age}",
+	Short: "Choose where tokens and client secrets are stored",
+	Long: `Select the secret storage backend used for OAuth tokens and client
+secrets (api_key, oauth_client_secret).
+
+"file" (the default) stores them inline in ~/.tom/token.json and the active
+profile's config file.
+"keyring" stores them in the OS-native credential store (Keychain on macOS,
+Secret Service on Linux, Credential Manager on Windows) instead.
+"age" stores them in an age-encrypted ~/.tom/secrets.age file, protected by
+a passphrase from TOM_SECRETS_PASSPHRASE or a terminal prompt.
+
+Switching backends migrates any already-stored token immediately, so you
+don't need to re-authenticate. Client secrets already saved in a profile's
+config file migrate the next time that profile is saved (for example via
+'config set-secret' or 'config create').`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backend := args[0]
+		if err := auth.SetSecretBackend(configDir, backend); err != nil {
+			return err
+		}
+
+		fmt.Printf("Secret backend set to '%s'\n", backend)
+		return nil
+	},
+}
+
+var configSetSecretFields = map[string]func(cfg *auth.Config, value string){
+	"api_key":             func(cfg *auth.Config, value string) { cfg.APIKey = value },
+	"oauth_client_secret": func(cfg *auth.Config, value string) { cfg.OAuthClientSecret = value },
+}
+
+var configSetSecretCmd = &cobra.Command{
+	Use:   "set-secret <field>",
+	Short: "Store a sensitive config field via the secret backend",
+	Long: `Prompt for a value and store it via the backend chosen with
+'tomclient config set-secret-backend' (file, keyring, or age; defaults to
+"file"). With the keyring or age backend, the field's plaintext value in the
+active profile is replaced with an opaque "secret://<backend>/<field>"
+reference that's resolved back on load; with the file backend, the value is
+saved in plaintext in the profile.
+
+Supported fields: api_key, oauth_client_secret.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		field := args[0]
+		setField, ok := configSetSecretFields[field]
+		if !ok {
+			return fmt.Errorf("unsupported field '%s' - must be one of: api_key, oauth_client_secret", field)
+		}
+
+		backend := auth.GetSecretBackend(configDir)
+
+		value, err := auth.PromptSecret(fmt.Sprintf("Enter value for %s: ", field))
+		if err != nil {
+			return err
+		}
+
+		cfg, err := auth.LoadConfigWithProfile(configDir, profile)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if backend != "file" {
+			store := auth.NewSecretStore(backend, cfg.ConfigDir)
+			if err := store.Set(field, value); err != nil {
+				return fmt.Errorf("failed to store secret: %w", err)
+			}
+			value = fmt.Sprintf("secret://%s/%s", backend, field)
+		}
+		setField(cfg, value)
+
+		activeProfile := profile
+		if activeProfile == "" {
+			activeProfile, err = auth.GetCurrentProfile(configDir)
+			if err != nil {
+				return err
+			}
+		}
+
+		if activeProfile != "" {
+			err = auth.SaveProfile(cfg, configDir, activeProfile)
+		} else {
+			err = cfg.Save()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		fmt.Printf("Stored %s via '%s' secret backend\n", field, backend)
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configListCmd)
 	configCmd.AddCommand(configUseCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configCreateCmd)
+	configCmd.AddCommand(configDeleteCmd)
+	configCmd.AddCommand(configRenameCmd)
+	configCmd.AddCommand(configCurrentCmd)
+	configCmd.AddCommand(configSetSecretBackendCmd)
+	configCmd.AddCommand(configSetSecretCmd)
 
 	configCreateCmd.Flags().StringVar(&configFromProfile, "from", "", "Copy settings from an existing profile")
 }
@@ -2,11 +2,29 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"tomclient/auth"
+	"tomclient/output"
+	"tomclient/tomapi"
 )
 
+// deviceResult is the structured form of a device command's output, used
+// when --output selects json/yaml/csv instead of the default raw text.
+type deviceResult struct {
+	Device  string `json:"device" yaml:"device"`
+	Command string `json:"command" yaml:"command"`
+	Output  string `json:"output" yaml:"output"`
+}
+
+var deviceResultColumns = []output.Column{
+	{Header: "DEVICE", Value: func(r interface{}) string { return r.(deviceResult).Device }},
+	{Header: "COMMAND", Value: func(r interface{}) string { return r.(deviceResult).Command }},
+	{Header: "OUTPUT", Value: func(r interface{}) string { return r.(deviceResult).Output }},
+}
+
 var (
 	deviceTimeout      int
 	deviceWait         bool
@@ -16,13 +34,19 @@ var (
 	deviceCache        bool
 	deviceCacheTTL     int
 	deviceCacheRefresh bool
+	deviceRetryTimeout time.Duration
+	deviceRetrySleep   time.Duration
 )
 
 var deviceCmd = &cobra.Command{
 	Use:   "device <device-name> <command>",
 	Short: "Run command on a network device",
 	Long: `Execute a command on a specific network device through the Tom API.
-Supports credential override and timeout configuration.`,
+Supports credential override and timeout configuration.
+
+--cache/--cache-ttl/--cache-refresh also gate a local on-disk result cache
+(~/.tom/cmd_cache) checked before the API is reached; a hit never
+leaves the machine. Use 'tomclient cache prune' to clear expired entries.`,
 	Example: `  tomclient device router1 "show version" --timeout=30
   tomclient device switch2 "show interface" -t 60 --raw
   tomclient device -u admin -p secret router3 "show running-config"`,
@@ -68,25 +92,74 @@ Supports credential override and timeout configuration.`,
 			cacheTTL = &cfg.CacheTTL
 		}
 
+		localTTL := deviceCacheTTL
+		if localTTL <= 0 {
+			localTTL = cfg.CacheTTL
+		}
+		if localTTL <= 0 {
+			localTTL = 300
+		}
+
+		format := getOutputFormat()
+
+		if useCache && !deviceCacheRefresh {
+			if cached, found, cerr := auth.LoadCommandCache(cfg.ConfigDir, deviceName, command); cerr == nil && found {
+				renderDeviceResult(format, deviceName, command, cached)
+				return
+			}
+		}
+
 		var result string
 		var err error
 
-		if deviceUser != "" || devicePass != "" {
+		switch {
+		case deviceRetryTimeout > 0 && (deviceUser != "" || devicePass != ""):
+			result, _, err = client.SendDeviceCommandWithAuthAndRetry(
+				deviceName, command, deviceUser, devicePass,
+				deviceWait, deviceRaw, deviceTimeout,
+				useCache, cacheTTL, deviceCacheRefresh,
+				tomapi.PollOptions{Interval: deviceRetrySleep, RetryTimeout: deviceRetryTimeout},
+			)
+		case deviceRetryTimeout > 0:
+			result, _, err = client.SendDeviceCommandWithRetry(deviceName, command, deviceWait, deviceRaw,
+				useCache, cacheTTL, deviceCacheRefresh,
+				tomapi.PollOptions{Interval: deviceRetrySleep, RetryTimeout: deviceRetryTimeout})
+		case deviceUser != "" || devicePass != "":
 			result, err = client.SendDeviceCommandWithAuth(
 				deviceName, command, deviceUser, devicePass,
 				deviceWait, deviceRaw, deviceTimeout,
 				useCache, cacheTTL, deviceCacheRefresh,
 			)
-		} else {
+		default:
 			result, err = client.SendDeviceCommand(deviceName, command, deviceWait, deviceRaw,
 				useCache, cacheTTL, deviceCacheRefresh)
 		}
 
 		handleError(err)
-		fmt.Print(result)
+
+		if useCache {
+			if cerr := auth.SaveCommandCache(cfg.ConfigDir, deviceName, command, result, localTTL); cerr != nil {
+				fmt.Printf("Warning: failed to save command cache: %v\n", cerr)
+			}
+		}
+
+		renderDeviceResult(format, deviceName, command, result)
 	},
 }
 
+// renderDeviceResult prints a device command's output: raw text for the
+// default table format (unchanged from before --output existed), or
+// json/yaml/csv via the shared output renderer.
+func renderDeviceResult(format output.Format, deviceName, command, result string) {
+	if format == output.FormatTable {
+		fmt.Print(result)
+		return
+	}
+
+	res := deviceResult{Device: deviceName, Command: command, Output: result}
+	handleError(output.Render(os.Stdout, format, res, deviceResultColumns, []interface{}{res}))
+}
+
 func init() {
 	rootCmd.AddCommand(deviceCmd)
 
@@ -101,6 +174,11 @@ func init() {
 	deviceCmd.Flags().IntVarP(&deviceCacheTTL, "cache-ttl", "T", 0, "Cache TTL in seconds (0 uses server default)")
 	deviceCmd.Flags().BoolVarP(&deviceCacheRefresh, "cache-refresh", "R", false, "Force refresh cached result")
 
+	// Retry on transient errors (network issues, 502/503/504); invalid
+	// credentials and other fatal errors are never retried.
+	deviceCmd.Flags().DurationVar(&deviceRetryTimeout, "retry-timeout", 0, "Keep retrying on transient errors until this duration elapses (0 disables retries)")
+	deviceCmd.Flags().DurationVar(&deviceRetrySleep, "retry-sleep", 2*time.Second, "Initial sleep between retries (doubles up to a cap)")
+
 	// Authentication flags
 	deviceCmd.Flags().StringVarP(&deviceUser, "username", "u", "", "Override username for authentication")
 	deviceCmd.Flags().StringVarP(&devicePass, "password", "p", "", "Override password for authentication")
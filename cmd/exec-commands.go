@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"tomclient/internal"
+)
+
+var (
+	execInputDir       string
+	execResultsDir     string
+	execConcurrency    int
+	execCanary         int
+	execDryRun         bool
+	execRPS            float64
+	execMaxFailures    int
+	execMaxConsecutive int
+	execResume         bool
+)
+
+var execCommandsCmd = &cobra.Command{
+	Use:   "exec-commands",
+	Short: "Execute generated deletion command files against their devices",
+	Long: `Reads the *_delete_ssn_interfaces.txt files produced by parse-interfaces and
+pushes each device's commands through the Tom API, closing the loop between
+parse-interfaces and execution.
+
+Supports a staged rollout: --canary N runs the first N devices sequentially and
+pauses for confirmation before continuing with the rest. --dry-run never contacts
+the API - it only prints the commands that would be sent. --max-failures and
+--max-consecutive-failures act as a circuit breaker that aborts the batch once too
+many devices fail, and --resume skips devices already marked succeeded in a
+previous run's results directory.
+
+Generated command files are not executed automatically by parse-interfaces - this
+command is the explicit, reviewable step that does so.`,
+	Example: `  tomclient exec-commands --input-dir=deletion-commands --dry-run
+  tomclient exec-commands --canary 5 --rps 2 --max-consecutive-failures 3
+  tomclient exec-commands --resume`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.ExecCommands(client, internal.ExecCommandsOptions{
+			InputDir:       execInputDir,
+			ResultsDir:     execResultsDir,
+			Concurrency:    execConcurrency,
+			Canary:         execCanary,
+			DryRun:         execDryRun,
+			RPS:            execRPS,
+			MaxFailures:    execMaxFailures,
+			MaxConsecutive: execMaxConsecutive,
+			Resume:         execResume,
+		})
+		handleError(err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(execCommandsCmd)
+
+	execCommandsCmd.Flags().StringVarP(&execInputDir, "input-dir", "i", "deletion-commands", "Directory containing deletion command files")
+	execCommandsCmd.Flags().StringVar(&execResultsDir, "results-dir", "results", "Directory for per-device result logs")
+	execCommandsCmd.Flags().IntVarP(&execConcurrency, "concurrency", "c", 10, "Number of concurrent workers")
+	execCommandsCmd.Flags().IntVar(&execCanary, "canary", 0, "Run the first N devices sequentially and pause for confirmation before continuing")
+	execCommandsCmd.Flags().BoolVarP(&execDryRun, "dry-run", "n", false, "Print commands instead of sending them")
+	execCommandsCmd.Flags().Float64Var(&execRPS, "rps", 0, "Maximum requests per second (0 disables rate limiting)")
+	execCommandsCmd.Flags().IntVar(&execMaxFailures, "max-failures", 0, "Abort after this many total device failures (0 disables)")
+	execCommandsCmd.Flags().IntVar(&execMaxConsecutive, "max-consecutive-failures", 5, "Abort after this many consecutive device failures (0 disables)")
+	execCommandsCmd.Flags().BoolVar(&execResume, "resume", false, "Skip devices already marked succeeded in results-dir")
+
+	execCommandsCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if execConcurrency < 1 {
+			return fmt.Errorf("concurrency must be at least 1, got %d", execConcurrency)
+		}
+		if execCanary < 0 {
+			return fmt.Errorf("canary must be non-negative, got %d", execCanary)
+		}
+		return nil
+	}
+}
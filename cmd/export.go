@@ -1,47 +1,155 @@
 package cmd
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"tomclient/output"
+	"tomclient/tomapi"
 )
 
 var (
-	exportFilter string
-	exportFormat string
+	exportFilter     string
+	exportFormat     string
+	exportOutputFile string
+	exportFields     string
 )
 
+// exportCSVFields maps the supported --fields column names to extractors
+// over a streamed Device record.
+var exportCSVFields = map[string]func(d tomapi.Device) string{
+	"hostname":       func(d tomapi.Device) string { return d.Hostname },
+	"host":           func(d tomapi.Device) string { return d.Host },
+	"port":           func(d tomapi.Device) string { return strconv.Itoa(d.Port) },
+	"adapter":        func(d tomapi.Device) string { return d.Adapter },
+	"adapter_driver": func(d tomapi.Device) string { return d.AdapterDriver },
+	"credential_id":  func(d tomapi.Device) string { return d.CredentialID },
+}
+
+var defaultExportCSVFields = []string{"hostname", "host", "port", "adapter", "credential_id"}
+
 var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "Export inventory from Tom API",
 	Long: `Export device inventory in various formats.
-Optionally filter the results using predefined filters.`,
+Optionally filter the results using predefined filters.
+
+ndjson and csv stream records as they arrive from the API and flush after
+each one, so large exports can be piped through jq/awk without buffering the
+whole inventory in memory.`,
 	Example: `  tomclient export --filter=routers --format=json
-  tomclient export -f switches -o pretty`,
+  tomclient export -f switches -o pretty
+  tomclient export -o ndjson --output-file inventory.ndjson
+  tomclient export -o csv --fields hostname,host,port`,
 	Run: func(cmd *cobra.Command, args []string) {
-		inventory, err := client.ExportInventory(exportFilter)
-		handleError(err)
+		var out io.Writer = os.Stdout
+		if exportOutputFile != "" {
+			file, err := os.Create(exportOutputFile)
+			handleError(err)
+			defer file.Close()
+			out = file
+		}
 
 		switch exportFormat {
-		case "json":
-			data, err := json.Marshal(inventory)
+		case "json", "pretty":
+			inventory, err := client.ExportInventory(exportFilter)
 			handleError(err)
-			fmt.Println(string(data))
-		case "pretty":
-			prettyJSON, err := json.MarshalIndent(inventory, "", "  ")
+
+			var data []byte
+			if exportFormat == "pretty" {
+				data, err = json.MarshalIndent(inventory, "", "  ")
+			} else {
+				data, err = json.Marshal(inventory)
+			}
 			handleError(err)
-			fmt.Println(string(prettyJSON))
+			fmt.Fprintln(out, string(data))
+		case "yaml":
+			inventory, err := client.ExportInventory(exportFilter)
+			handleError(err)
+
+			data, err := yaml.Marshal(inventory)
+			handleError(err)
+			fmt.Fprint(out, string(data))
+		case "ndjson":
+			handleError(streamExportNDJSON(out, exportFilter))
+		case "csv":
+			handleError(streamExportCSV(out, exportFilter, exportFields))
 		default:
 			handleError(fmt.Errorf("unsupported format: %s", exportFormat))
 		}
 	},
 }
 
+// streamExportNDJSON writes one JSON object per device, flushing after each
+// record so a consumer reading the output doesn't have to wait for the full
+// export to complete.
+func streamExportNDJSON(out io.Writer, filter string) error {
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+
+	devices, errs := client.ExportInventoryStream(filter)
+	for device := range devices {
+		if err := enc.Encode(device); err != nil {
+			return fmt.Errorf("failed to encode device %q: %w", device.Hostname, err)
+		}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("failed to flush output: %w", err)
+		}
+	}
+
+	return <-errs
+}
+
+// streamExportCSV writes the devices from ExportInventoryStream as CSV rows
+// through a shared output.StreamWriter, flushing after each one.
+// fieldsFlag is a comma-separated list of column names from
+// exportCSVFields; an empty value uses defaultExportCSVFields.
+func streamExportCSV(out io.Writer, filter, fieldsFlag string) error {
+	fields := defaultExportCSVFields
+	if fieldsFlag != "" {
+		fields = strings.Split(fieldsFlag, ",")
+	}
+
+	columns := make([]output.Column, len(fields))
+	for i, field := range fields {
+		extract, ok := exportCSVFields[field]
+		if !ok {
+			return fmt.Errorf("unknown CSV field %q (known fields: hostname, host, port, adapter, adapter_driver, credential_id)", field)
+		}
+		columns[i] = output.Column{
+			Header: field,
+			Value:  func(row interface{}) string { return extract(row.(tomapi.Device)) },
+		}
+	}
+
+	sw := output.NewStreamWriter(out, output.FormatCSV, columns)
+
+	devices, errs := client.ExportInventoryStream(filter)
+	for device := range devices {
+		if err := sw.WriteRow(device); err != nil {
+			return fmt.Errorf("failed to write row for %q: %w", device.Hostname, err)
+		}
+	}
+	if err := sw.Close(); err != nil {
+		return err
+	}
+
+	return <-errs
+}
+
 func init() {
 	rootCmd.AddCommand(exportCmd)
 
 	// POSIX-style flags with both long and short versions
 	exportCmd.Flags().StringVarP(&exportFilter, "filter", "f", "", "Filter name to apply (optional)")
-	exportCmd.Flags().StringVarP(&exportFormat, "output", "o", "pretty", "Output format: json, pretty")
-}
\ No newline at end of file
+	exportCmd.Flags().StringVarP(&exportFormat, "output", "o", "pretty", "Output format: json, pretty, ndjson, csv, yaml")
+	exportCmd.Flags().StringVar(&exportOutputFile, "output-file", "", "Write to this file instead of stdout")
+	exportCmd.Flags().StringVar(&exportFields, "fields", "", "Comma-separated CSV columns (default: hostname,host,port,adapter,credential_id)")
+}
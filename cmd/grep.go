@@ -1,33 +1,45 @@
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"tomclient/auth"
+	"tomclient/tomapi"
 )
 
 var (
-	grepDevices     string
-	grepFilter      string
-	grepMatch       string
-	grepCommand     string
-	grepContext     int
-	grepBefore      int
-	grepAfter       int
-	grepSection     bool
-	grepParent      bool
-	grepParentLine  bool
-	grepIgnoreCase  bool
-	grepNoColor     bool
-	grepLineNumbers bool
-	grepNoCache     bool
-	grepParallel    int
+	grepDevices      string
+	grepFilter       string
+	grepMatch        string
+	grepCommand      string
+	grepContext      int
+	grepBefore       int
+	grepAfter        int
+	grepSection      bool
+	grepParent       bool
+	grepParentLine   bool
+	grepIgnoreCase   bool
+	grepNoColor      bool
+	grepLineNumbers  bool
+	grepNoCache      bool
+	grepCacheTTL     int
+	grepCacheRefresh bool
+	grepParallel     int
+	grepOutput       string
+	grepSort         string
+	grepRetryTimeout time.Duration
+	grepRetrySleep   time.Duration
 )
 
 type grepResult struct {
@@ -37,8 +49,13 @@ type grepResult struct {
 }
 
 type matchBlock struct {
-	Lines      []string
-	MatchIndex int // index of the matching line within Lines
+	Lines        []string
+	MatchIndex   int   // index of the first matching line within Lines
+	MatchIndices []int // index of every matching line within Lines, for underlining after a merge
+	Start, End   int   // this block's absolute [start,end) range in the original lines slice
+	Sparse       bool  // true for --parent-line blocks, whose Lines skip lines between Start and End
+
+	matchLines []int // absolute indices (into the original lines slice) of every match folded into this block
 }
 
 var grepCmd = &cobra.Command{
@@ -53,13 +70,34 @@ Context Modes:
   -B N           Show N lines before each match
   --section      Show matching line and all indented children (Cisco section-style)
   --parent       Show parent block header and all siblings at same indentation
-  --parent-line  Show just the parent header line and the match`,
+  --parent-line  Show just the parent header line and the match
+
+Output Formats (--output/-o):
+  text   Colorized, human-readable output (default)
+  json   Array of {device, matches} objects
+  jsonl  One {device, matches} object per line, streamed as each device completes
+  csv    device,line_number,match_line rows
+
+Use --retry-timeout to retry a device's query on transient errors (network
+issues, 502/503/504) instead of failing immediately; each device retries
+independently, so a slow or flaky one doesn't hold up the others.
+
+Sort Modes (--sort):
+  completion  Print each device's result as soon as it completes (default)
+  name        Buffer every result and print alphabetically by device name
+
+--cache-ttl/--cache-refresh/--no-cache also gate a local on-disk result cache
+(~/.tom/cmd_cache), checked per device before the API is reached; a hit
+never leaves the machine. Use 'tomclient cache prune' to clear expired
+entries.`,
 	Example: `  tomclient grep "ip route.*10.0.0" --devices SCCSNJ75AS1,SCCSNJ75AS2
   tomclient grep "interface.*Loopback" --match "^SCCSNJ" -C 3
   tomclient grep "bgp neighbor" --section --match ".*AS[12]"
   tomclient grep "shutdown" -P --devices SCCSNJ75AS1
   tomclient grep "ip address" -p --match ".*AS1$"
-  tomclient grep "permit" --command "show access-lists" -A 2`,
+  tomclient grep "permit" --command "show access-lists" -A 2
+  tomclient grep "shutdown" --match ".*AS1$" -o jsonl
+  tomclient grep "interface" --match ".*AS1$" --sort name`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
@@ -83,10 +121,29 @@ Context Modes:
 			return fmt.Errorf("no devices specified; use --devices, --prefix, --match, or --filter")
 		}
 
-		results := queryDevicesParallel(devices, re)
-		printResults(results, re)
+		cfg, err := auth.LoadConfig(configDir)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		outputter, err := newOutputter(grepOutput)
+		if err != nil {
+			return err
+		}
+
+		var resultsChan <-chan grepResult
+		switch grepSort {
+		case "", "completion":
+			resultsChan = queryDevicesStream(devices, re, cfg)
+		case "name":
+			results := queryDevicesParallel(devices, re, cfg)
+			sort.Slice(results, func(i, j int) bool { return results[i].Device < results[j].Device })
+			resultsChan = sliceToChan(results)
+		default:
+			return fmt.Errorf("invalid --sort value: %s (must be one of: completion, name)", grepSort)
+		}
 
-		return nil
+		return outputter.Output(resultsChan, re)
 	},
 }
 
@@ -142,7 +199,7 @@ func resolveDevices() ([]string, error) {
 	return cache.Devices, nil
 }
 
-func queryDevicesParallel(devices []string, re *regexp.Regexp) []grepResult {
+func queryDevicesParallel(devices []string, re *regexp.Regexp, cfg *auth.Config) []grepResult {
 	results := make([]grepResult, len(devices))
 	var wg sync.WaitGroup
 
@@ -159,7 +216,7 @@ func queryDevicesParallel(devices []string, re *regexp.Regexp) []grepResult {
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			results[idx] = queryDevice(dev, re)
+			results[idx] = queryDevice(dev, re, cfg)
 		}(i, device)
 	}
 
@@ -167,17 +224,104 @@ func queryDevicesParallel(devices []string, re *regexp.Regexp) []grepResult {
 	return results
 }
 
-func queryDevice(device string, re *regexp.Regexp) grepResult {
+// queryDevicesStream runs the same parallel worker pool as
+// queryDevicesParallel, but sends each grepResult as soon as its device
+// query finishes instead of waiting for all of them, in completion order
+// rather than device order.
+func queryDevicesStream(devices []string, re *regexp.Regexp, cfg *auth.Config) <-chan grepResult {
+	out := make(chan grepResult, len(devices))
+	var wg sync.WaitGroup
+
+	parallel := grepParallel
+	if parallel <= 0 {
+		parallel = 10
+	}
+	sem := make(chan struct{}, parallel)
+
+	for _, device := range devices {
+		wg.Add(1)
+		go func(dev string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			out <- queryDevice(dev, re, cfg)
+		}(device)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// sliceToChan adapts an already-collected result slice to the channel shape
+// Outputter.Output expects, so text/json/csv can share the same wiring as
+// the streaming jsonl case.
+func sliceToChan(results []grepResult) <-chan grepResult {
+	ch := make(chan grepResult, len(results))
+	for _, r := range results {
+		ch <- r
+	}
+	close(ch)
+	return ch
+}
+
+// queryDevice runs the grep command against a single device. If
+// --retry-timeout is set, transient errors (network issues, 502/503/504) are
+// retried with backoff until it elapses; a fatal error like invalid
+// credentials is never retried, and a result.Error that ran out of retries
+// says so ("gave up after N attempts") rather than looking identical to one
+// that failed outright.
+//
+// Before hitting the API, a local on-disk cache (~/.tom/cmd_cache) is
+// checked for this (device, command) pair; a hit never leaves the machine.
+// --no-cache skips both this local cache and the server's own, and
+// --cache-refresh forces a fresh fetch while still saving the result locally
+// for next time.
+func queryDevice(device string, re *regexp.Regexp, cfg *auth.Config) grepResult {
 	command := grepCommand
 	if command == "" {
 		command = "show running-config"
 	}
 
-	output, err := client.SendDeviceCommand(device, command, true, true, !grepNoCache, nil, false)
+	useCache := !grepNoCache
+
+	if useCache && !grepCacheRefresh {
+		if cached, found, cerr := auth.LoadCommandCache(cfg.ConfigDir, device, command); cerr == nil && found {
+			lines := strings.Split(cached, "\n")
+			return grepResult{Device: device, Matches: findMatches(lines, re)}
+		}
+	}
+
+	var output string
+	var err error
+
+	if grepRetryTimeout > 0 {
+		output, _, err = client.SendDeviceCommandWithRetry(device, command, true, true, useCache, nil, grepCacheRefresh,
+			tomapi.PollOptions{Interval: grepRetrySleep, RetryTimeout: grepRetryTimeout})
+	} else {
+		output, err = client.SendDeviceCommand(device, command, true, true, useCache, nil, grepCacheRefresh)
+	}
 	if err != nil {
 		return grepResult{Device: device, Error: err}
 	}
 
+	if useCache {
+		ttl := grepCacheTTL
+		if ttl <= 0 {
+			ttl = cfg.CacheTTL
+		}
+		if ttl <= 0 {
+			ttl = 300
+		}
+		if cerr := auth.SaveCommandCache(cfg.ConfigDir, device, command, output, ttl); cerr != nil {
+			fmt.Fprintf(os.Stderr, "%s: warning: failed to save command cache: %v\n", device, cerr)
+		}
+	}
+
 	lines := strings.Split(output, "\n")
 	matches := findMatches(lines, re)
 
@@ -205,7 +349,7 @@ func findMatches(lines []string, re *regexp.Regexp) []matchBlock {
 		}
 	}
 
-	return mergeOverlappingBlocks(matches)
+	return mergeOverlappingBlocks(lines, matches)
 }
 
 func extractContext(lines []string, matchIdx int) matchBlock {
@@ -227,8 +371,12 @@ func extractContext(lines []string, matchIdx int) matchBlock {
 	}
 
 	return matchBlock{
-		Lines:      lines[start:end],
-		MatchIndex: matchIdx - start,
+		Lines:        lines[start:end],
+		MatchIndex:   matchIdx - start,
+		MatchIndices: []int{matchIdx - start},
+		Start:        start,
+		End:          end,
+		matchLines:   []int{matchIdx},
 	}
 }
 
@@ -254,8 +402,12 @@ func extractSection(lines []string, matchIdx int) matchBlock {
 	}
 
 	return matchBlock{
-		Lines:      lines[start:end],
-		MatchIndex: 0,
+		Lines:        lines[start:end],
+		MatchIndex:   0,
+		MatchIndices: []int{0},
+		Start:        start,
+		End:          end,
+		matchLines:   []int{matchIdx},
 	}
 }
 
@@ -300,8 +452,12 @@ func extractParentBlock(lines []string, matchIdx int) matchBlock {
 	}
 
 	return matchBlock{
-		Lines:      lines[parentIdx:end],
-		MatchIndex: matchIdx - parentIdx,
+		Lines:        lines[parentIdx:end],
+		MatchIndex:   matchIdx - parentIdx,
+		MatchIndices: []int{matchIdx - parentIdx},
+		Start:        parentIdx,
+		End:          end,
+		matchLines:   []int{matchIdx},
 	}
 }
 
@@ -326,14 +482,23 @@ func extractParentLine(lines []string, matchIdx int) matchBlock {
 	// If no parent found (already at root level), just return the match
 	if parentIdx == -1 {
 		return matchBlock{
-			Lines:      []string{lines[matchIdx]},
-			MatchIndex: 0,
+			Lines:        []string{lines[matchIdx]},
+			MatchIndex:   0,
+			MatchIndices: []int{0},
+			Start:        matchIdx,
+			End:          matchIdx + 1,
+			matchLines:   []int{matchIdx},
 		}
 	}
 
 	return matchBlock{
-		Lines:      []string{lines[parentIdx], lines[matchIdx]},
-		MatchIndex: 1,
+		Lines:        []string{lines[parentIdx], lines[matchIdx]},
+		MatchIndex:   1,
+		MatchIndices: []int{1},
+		Start:        parentIdx,
+		End:          matchIdx + 1,
+		Sparse:       true,
+		matchLines:   []int{matchIdx},
 	}
 }
 
@@ -351,17 +516,245 @@ func getIndent(line string) int {
 	return indent
 }
 
-func mergeOverlappingBlocks(blocks []matchBlock) []matchBlock {
+// mergeOverlappingBlocks coalesces blocks whose absolute [Start,End) ranges
+// overlap or touch into a single block, so context windows that collide
+// (e.g. two matches 2 lines apart under -C 3) print once instead of twice
+// with a "--" separator between duplicated lines. --parent/--parent-line
+// blocks that share the same anchor (Start) are merged too, even though
+// their ranges may not overlap in the dense sense.
+func mergeOverlappingBlocks(lines []string, blocks []matchBlock) []matchBlock {
 	if len(blocks) <= 1 {
 		return blocks
 	}
 
-	// For now, just return as-is; could implement merging later
-	// to avoid duplicate output when matches are close together
-	return blocks
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Start < blocks[j].Start })
+
+	merged := []matchBlock{blocks[0]}
+	for _, b := range blocks[1:] {
+		last := merged[len(merged)-1]
+
+		switch {
+		case last.Sparse || b.Sparse:
+			if b.Start == last.Start {
+				merged[len(merged)-1] = mergeSparseBlocks(lines, last, b)
+				continue
+			}
+		case b.Start <= last.End:
+			merged[len(merged)-1] = mergeDenseBlocks(lines, last, b)
+			continue
+		}
+
+		merged = append(merged, b)
+	}
+
+	return merged
 }
 
-func printResults(results []grepResult, re *regexp.Regexp) {
+// mergeDenseBlocks unions two contiguous blocks by reslicing the original
+// lines, then recomputes each folded-in match's index relative to the new
+// start so the printer can underline every one of them.
+func mergeDenseBlocks(lines []string, a, b matchBlock) matchBlock {
+	start, end := a.Start, a.End
+	if b.Start < start {
+		start = b.Start
+	}
+	if b.End > end {
+		end = b.End
+	}
+
+	matchLines := dedupAppend(a.matchLines, b.matchLines)
+	indices := make([]int, len(matchLines))
+	for i, ml := range matchLines {
+		indices[i] = ml - start
+	}
+
+	return matchBlock{
+		Lines:        lines[start:end],
+		MatchIndex:   indices[0],
+		MatchIndices: indices,
+		Start:        start,
+		End:          end,
+		matchLines:   matchLines,
+	}
+}
+
+// mergeSparseBlocks merges two --parent/--parent-line blocks that share the
+// same anchor line: the anchor is kept once, followed by every distinct
+// match line it covers, in source order.
+func mergeSparseBlocks(lines []string, a, b matchBlock) matchBlock {
+	matchLines := dedupAppend(a.matchLines, b.matchLines)
+
+	out := []string{lines[a.Start]}
+	indices := make([]int, 0, len(matchLines))
+	for _, ml := range matchLines {
+		out = append(out, lines[ml])
+		indices = append(indices, len(out)-1)
+	}
+
+	end := a.End
+	if b.End > end {
+		end = b.End
+	}
+
+	return matchBlock{
+		Lines:        out,
+		MatchIndex:   indices[0],
+		MatchIndices: indices,
+		Start:        a.Start,
+		End:          end,
+		Sparse:       true,
+		matchLines:   matchLines,
+	}
+}
+
+// dedupAppend merges two sorted, already-deduplicated index slices into one
+// sorted, deduplicated slice.
+func dedupAppend(a, b []int) []int {
+	seen := make(map[int]bool, len(a)+len(b))
+	out := make([]int, 0, len(a)+len(b))
+	for _, idx := range append(append([]int{}, a...), b...) {
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		out = append(out, idx)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// Outputter renders grepResults arriving on a channel in a particular
+// format. Results may arrive in completion order (--sort completion, the
+// default) or already sorted by device name (--sort name); an Outputter
+// that cares about order must sort internally rather than assume one.
+type Outputter interface {
+	Output(results <-chan grepResult, re *regexp.Regexp) error
+}
+
+// newOutputter returns the Outputter for the given --output format name.
+func newOutputter(format string) (Outputter, error) {
+	switch format {
+	case "", "text":
+		return textOutputter{}, nil
+	case "json":
+		return jsonOutputter{}, nil
+	case "jsonl":
+		return jsonlOutputter{}, nil
+	case "csv":
+		return csvOutputter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s (must be one of: text, json, jsonl, csv)", format)
+	}
+}
+
+// jsonMatch is one matched block within a device's grep results, shared by
+// the json and jsonl outputters.
+type jsonMatch struct {
+	StartLine     int      `json:"start_line"`
+	MatchLine     string   `json:"match_line"`
+	Context       []string `json:"context"`
+	SectionHeader string   `json:"section_header,omitempty"`
+}
+
+// jsonDevice is one device's grep results, shared by the json and jsonl
+// outputters.
+type jsonDevice struct {
+	Device  string      `json:"device"`
+	Error   string      `json:"error,omitempty"`
+	Matches []jsonMatch `json:"matches"`
+}
+
+// toJSONDevice converts a grepResult into the shape emitted by json/jsonl.
+func toJSONDevice(result grepResult) jsonDevice {
+	out := jsonDevice{Device: result.Device}
+	if result.Error != nil {
+		out.Error = result.Error.Error()
+		return out
+	}
+
+	for _, block := range result.Matches {
+		sectionHeader := ""
+		if (grepParent || grepParentLine) && len(block.Lines) > 0 {
+			sectionHeader = block.Lines[0]
+		}
+
+		for _, idx := range block.MatchIndices {
+			out.Matches = append(out.Matches, jsonMatch{
+				StartLine:     block.Start + 1,
+				MatchLine:     block.Lines[idx],
+				Context:       block.Lines,
+				SectionHeader: sectionHeader,
+			})
+		}
+	}
+
+	return out
+}
+
+type jsonOutputter struct{}
+
+func (jsonOutputter) Output(results <-chan grepResult, re *regexp.Regexp) error {
+	var devices []jsonDevice
+	for result := range results {
+		devices = append(devices, toJSONDevice(result))
+	}
+
+	data, err := json.MarshalIndent(devices, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+type jsonlOutputter struct{}
+
+func (jsonlOutputter) Output(results <-chan grepResult, re *regexp.Regexp) error {
+	enc := json.NewEncoder(os.Stdout)
+	for result := range results {
+		if err := enc.Encode(toJSONDevice(result)); err != nil {
+			return fmt.Errorf("failed to encode device %q: %w", result.Device, err)
+		}
+	}
+	return nil
+}
+
+type csvOutputter struct{}
+
+func (csvOutputter) Output(results <-chan grepResult, re *regexp.Regexp) error {
+	w := csv.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	if err := w.Write([]string{"device", "line_number", "match_line"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for result := range results {
+		if result.Error != nil {
+			fmt.Fprintf(os.Stderr, "%s: error: %v\n", result.Device, result.Error)
+			continue
+		}
+
+		for _, block := range result.Matches {
+			for k, idx := range block.MatchIndices {
+				row := []string{
+					result.Device,
+					strconv.Itoa(block.matchLines[k] + 1),
+					block.Lines[idx],
+				}
+				if err := w.Write(row); err != nil {
+					return fmt.Errorf("failed to write row for %q: %w", result.Device, err)
+				}
+			}
+		}
+	}
+
+	return w.Error()
+}
+
+type textOutputter struct{}
+
+func (textOutputter) Output(results <-chan grepResult, re *regexp.Regexp) error {
 	deviceColor := color.New(color.FgMagenta, color.Bold)
 	matchColor := color.New(color.FgRed, color.Bold)
 	lineNumColor := color.New(color.FgGreen)
@@ -372,7 +765,7 @@ func printResults(results []grepResult, re *regexp.Regexp) {
 	}
 
 	first := true
-	for _, result := range results {
+	for result := range results {
 		if result.Error != nil {
 			fmt.Fprintf(os.Stderr, "%s: error: %v\n", result.Device, result.Error)
 			continue
@@ -394,13 +787,18 @@ func printResults(results []grepResult, re *regexp.Regexp) {
 				sepColor.Println("--")
 			}
 
+			isMatchLine := make(map[int]bool, len(block.MatchIndices))
+			for _, idx := range block.MatchIndices {
+				isMatchLine[idx] = true
+			}
+
 			for i, line := range block.Lines {
 				if grepLineNumbers {
 					lineNum := fmt.Sprintf("%4d", i+1)
 					lineNumColor.Print(lineNum)
 
 					sep := ":"
-					if i == block.MatchIndex {
+					if isMatchLine[i] {
 						sep = ">"
 					}
 					sepColor.Printf("%s ", sep)
@@ -418,6 +816,8 @@ func printResults(results []grepResult, re *regexp.Regexp) {
 			}
 		}
 	}
+
+	return nil
 }
 
 func init() {
@@ -444,5 +844,14 @@ func init() {
 	grepCmd.Flags().BoolVar(&grepNoColor, "no-color", false, "Disable colored output")
 	grepCmd.Flags().BoolVarP(&grepLineNumbers, "line-numbers", "n", false, "Show line numbers")
 	grepCmd.Flags().BoolVar(&grepNoCache, "no-cache", false, "Disable caching (cache enabled by default)")
+	grepCmd.Flags().IntVar(&grepCacheTTL, "cache-ttl", 0, "Local command cache TTL in seconds (0 uses config default, falling back to 300)")
+	grepCmd.Flags().BoolVar(&grepCacheRefresh, "cache-refresh", false, "Bypass the local command cache and force a fresh fetch")
 	grepCmd.Flags().IntVar(&grepParallel, "parallel", 10, "Number of parallel device queries")
+	grepCmd.Flags().StringVarP(&grepOutput, "output", "o", "text", "Output format: text, json, jsonl, csv")
+	grepCmd.Flags().StringVar(&grepSort, "sort", "completion", "Result ordering: completion (stream as devices finish) or name (buffer, sorted alphabetically)")
+
+	// Retry on transient errors; each worker retries its own device
+	// independently, so one flaky device doesn't stall the others.
+	grepCmd.Flags().DurationVar(&grepRetryTimeout, "retry-timeout", 0, "Keep retrying a device query on transient errors until this duration elapses (0 disables retries)")
+	grepCmd.Flags().DurationVar(&grepRetrySleep, "retry-sleep", 2*time.Second, "Initial sleep between retries (doubles up to a cap)")
 }
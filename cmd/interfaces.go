@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"tomclient/internal"
+)
+
+var (
+	interfacesInputDir  string
+	interfacesPattern   string
+	interfacesOutput    string
+	interfacesFormat    string
+	interfacesPlanFile  string
+	interfacesSkipCache bool
+	interfacesApplyYes  bool
+)
+
+var interfacesCmd = &cobra.Command{
+	Use:   "interfaces",
+	Short: "Plan and apply interface deletion changes across devices",
+	Long: `Plan and apply interface deletion/reset changes generated from collected
+interface config files (see 'tomclient parse-interfaces' for the older,
+text-only version of this workflow).
+
+'plan' parses every matching file and writes a structured, reviewable
+ChangePlan (JSON or YAML) per device, including a rollback snippet for each
+interface. 'apply' reads those plans back and pushes the commands to each
+device through the Tom API, then invalidates that device's cache entry.`,
+}
+
+var interfacesPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Generate a structured change plan from collected interface configs",
+	Long: `Parse collected interface configuration files to find interfaces matching
+--pattern (like 'SSN' in description) and emit a ChangePlan per device: each
+interface's description, the exact commands that will delete/reset it, and a
+rollback snippet captured from its original config lines.
+
+The dialect (Cisco IOS/IOS-XE, NX-OS, Arista EOS, Juniper JunOS set-format,
+or Nokia SR OS) is auto-detected per file, same as 'parse-interfaces'.`,
+	Example: `  tomclient interfaces plan --input-dir=interfaces --pattern=SSN -o plan.json
+  tomclient interfaces plan -i ./interfaces --pattern SSN -o plan.yaml --format yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		pattern := filepath.Join(interfacesInputDir, "*_interfaces.txt")
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no interface files found in %s", interfacesInputDir)
+		}
+
+		var plans []*internal.ChangePlan
+		for _, file := range files {
+			deviceInfo, err := internal.ParseInterfaceConfig(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", file, err)
+				continue
+			}
+
+			var matching []internal.InterfaceInfo
+			for _, iface := range deviceInfo.Interfaces {
+				if strings.Contains(strings.ToUpper(iface.Description), strings.ToUpper(interfacesPattern)) {
+					matching = append(matching, iface)
+				}
+			}
+			if len(matching) == 0 {
+				continue
+			}
+
+			plans = append(plans, internal.GenerateChangePlan(deviceInfo.Hostname, deviceInfo.Dialect, matching))
+		}
+
+		if len(plans) == 0 {
+			fmt.Printf("No interfaces matching %q found\n", interfacesPattern)
+			return nil
+		}
+
+		var data []byte
+		switch interfacesFormat {
+		case "", "json":
+			data, err = json.MarshalIndent(plans, "", "  ")
+		case "yaml":
+			data, err = yaml.Marshal(plans)
+		default:
+			return fmt.Errorf("invalid --format value: %s (must be one of: json, yaml)", interfacesFormat)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to marshal change plan: %w", err)
+		}
+
+		if interfacesOutput == "" {
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if err := os.WriteFile(interfacesOutput, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", interfacesOutput, err)
+		}
+		fmt.Printf("Wrote change plan for %d device(s) to %s\n", len(plans), interfacesOutput)
+		return nil
+	},
+}
+
+var interfacesApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Push a previously generated change plan to its devices",
+	Long: `Read a ChangePlan file written by 'tomclient interfaces plan' and push its
+commands to each device via the Tom API, in order: EnterConfig, each
+interface's delete commands, then ExitConfig. The device's cache entry is
+invalidated afterwards so the next read reflects the change.
+
+Requires --yes to confirm - this command executes on real devices.`,
+	Example: `  tomclient interfaces apply --plan plan.json --yes
+  tomclient interfaces apply --plan plan.json --yes --no-invalidate-cache`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if !interfacesApplyYes {
+			return fmt.Errorf("--yes is required to apply a change plan to real devices")
+		}
+
+		data, err := os.ReadFile(interfacesPlanFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", interfacesPlanFile, err)
+		}
+
+		var plans []*internal.ChangePlan
+		if jsonErr := json.Unmarshal(data, &plans); jsonErr != nil {
+			if yamlErr := yaml.Unmarshal(data, &plans); yamlErr != nil {
+				return fmt.Errorf("failed to parse %s as JSON or YAML: %w", interfacesPlanFile, jsonErr)
+			}
+		}
+
+		for _, plan := range plans {
+			fmt.Printf("=== %s [%s]: %d interface(s) ===\n", plan.Hostname, plan.Dialect, len(plan.Interfaces))
+
+			commands := append([]string{}, plan.EnterConfig...)
+			for _, entry := range plan.Interfaces {
+				commands = append(commands, entry.Commands...)
+			}
+			commands = append(commands, plan.ExitConfig...)
+
+			var applyErr error
+			for _, command := range commands {
+				if _, err := client.SendDeviceCommand(plan.Hostname, command, true, true, false, nil, false); err != nil {
+					fmt.Fprintf(os.Stderr, "%s: error running %q: %v\n", plan.Hostname, command, err)
+					applyErr = err
+					break
+				}
+			}
+			if applyErr != nil {
+				continue
+			}
+
+			if !interfacesSkipCache {
+				if _, err := client.InvalidateDeviceCache(plan.Hostname); err != nil {
+					fmt.Fprintf(os.Stderr, "%s: warning: failed to invalidate cache: %v\n", plan.Hostname, err)
+				}
+			}
+			fmt.Printf("%s: applied\n", plan.Hostname)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(interfacesCmd)
+	interfacesCmd.AddCommand(interfacesPlanCmd)
+	interfacesCmd.AddCommand(interfacesApplyCmd)
+
+	interfacesPlanCmd.Flags().StringVarP(&interfacesInputDir, "input-dir", "i", "interfaces", "Directory containing interface config files")
+	interfacesPlanCmd.Flags().StringVar(&interfacesPattern, "pattern", "SSN", "Pattern to search for in interface descriptions")
+	interfacesPlanCmd.Flags().StringVarP(&interfacesOutput, "output", "o", "", "Write the change plan here instead of stdout")
+	interfacesPlanCmd.Flags().StringVar(&interfacesFormat, "format", "json", "Change plan format: json, yaml")
+
+	interfacesApplyCmd.Flags().StringVar(&interfacesPlanFile, "plan", "", "Change plan file to apply (JSON or YAML)")
+	interfacesApplyCmd.Flags().BoolVar(&interfacesApplyYes, "yes", false, "Confirm applying this plan to real devices")
+	interfacesApplyCmd.Flags().BoolVar(&interfacesSkipCache, "no-invalidate-cache", false, "Skip invalidating the device's Tom API cache entry after applying")
+	interfacesApplyCmd.MarkFlagRequired("plan")
+}
@@ -25,7 +25,11 @@ var parseInterfacesCmd = &cobra.Command{
 	Long: `Parse collected interface configuration files to find interfaces with specific patterns
 (like 'SSN' in description) and generate deletion commands for each device.
 
-IMPORTANT: This command only generates command files locally - it does NOT execute 
+Each file's vendor dialect (Cisco IOS/IOS-XE, NX-OS, Arista EOS, Juniper JunOS
+set-format, or Nokia SR OS) is auto-detected from its filename and contents,
+so deletion commands come out in that device's own syntax.
+
+IMPORTANT: This command only generates command files locally - it does NOT execute
 commands on remote devices. Generated files must be manually reviewed and executed.`,
 	Example: `  tomclient parse-interfaces --input-dir=interfaces --pattern=SSN
   tomclient parse-interfaces -i ./interfaces -p SSN --dry-run
@@ -89,14 +93,14 @@ commands on remote devices. Generated files must be manually reviewed and execut
 			// Generate deletion commands
 			var commands []string
 			if parseDetailed {
-				commands = internal.GenerateDeleteCommandsDetailed(matchingInterfaces)
+				commands = internal.GenerateDeleteCommandsDetailed(deviceInfo.Dialect, matchingInterfaces)
 			} else {
-				commands = internal.GenerateDeleteCommands(matchingInterfaces)
+				commands = internal.GenerateDeleteCommands(deviceInfo.Dialect, matchingInterfaces)
 			}
 
 			// Output commands
 			if parseDryRun {
-				fmt.Printf("\n=== %s (%d interfaces) ===\n", deviceInfo.Hostname, len(matchingInterfaces))
+				fmt.Printf("\n=== %s [%s] (%d interfaces) ===\n", deviceInfo.Hostname, deviceInfo.Dialect.Name(), len(matchingInterfaces))
 				for _, cmd := range commands {
 					fmt.Println(cmd)
 				}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"tomclient/pipeline"
+)
+
+var (
+	pipelineConfigFile  string
+	pipelineArtifactDir string
+)
+
+var pipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Run a configurable parse/enrich/assert/export pipeline over collected artifacts",
+}
+
+var pipelineRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a pipeline against collected inventory/interface artifacts",
+	Long: `Reinject collected artifacts (inventory/*_inventory.txt,
+interfaces/*_interfaces.txt) through a parse -> enrich -> assert -> export
+graph described by a YAML config, instead of going straight from raw
+artifacts to a fixed report. See pipeline.LoadConfig for the config shape.`,
+	Example: `  tomclient pipeline run --config=pipeline.yaml
+  tomclient pipeline run -c pipeline.yaml --artifacts-dir=.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := pipeline.LoadConfig(pipelineConfigFile)
+		if err != nil {
+			return err
+		}
+
+		result, err := pipeline.Run(cfg, pipelineArtifactDir)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range result.Assertions {
+			if !r.Passed {
+				failed++
+			}
+		}
+
+		fmt.Printf("Pipeline processed %d device(s), %d/%d assertion(s) failed.\n",
+			len(result.Records), failed, len(result.Assertions))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pipelineCmd)
+	pipelineCmd.AddCommand(pipelineRunCmd)
+
+	pipelineRunCmd.Flags().StringVarP(&pipelineConfigFile, "config", "c", "", "Pipeline YAML config file")
+	pipelineRunCmd.Flags().StringVar(&pipelineArtifactDir, "artifacts-dir", ".", "Directory containing inventory/ and interfaces/ subdirectories")
+
+	pipelineRunCmd.MarkFlagRequired("config")
+}
@@ -2,28 +2,47 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"tomclient/internal"
 )
 
 var (
-	reportInputDir  string
-	reportOutputFile string
+	reportInputDir    string
+	reportOutputFile  string
+	reportFormat      string
+	reportTextfileDir string
 )
 
 var reportCmd = &cobra.Command{
 	Use:   "report",
-	Short: "Generate CSV report from inventory files",
-	Long: `Parse inventory files and generate a comprehensive CSV report
-with device information, serial numbers, and age calculations.`,
+	Short: "Generate a report from inventory files",
+	Long: `Parse inventory files and generate a comprehensive report with device
+information, serial numbers, and age calculations.
+
+The output format is chosen via --format, or inferred from --output's file
+extension (.csv, .json, .xlsx, .md, .prom) when --format is not given.
+--format=prometheus (or --textfile-dir) writes a node_exporter textfile
+collector file instead: tom_device_component_age_years, tom_device_
+component_count, and tom_collection_timestamp_seconds gauges.`,
 	Example: `  tomclient report --input-dir=inventory --output=report.csv
-  tomclient report -i ./data -o devices.csv`,
+  tomclient report -i ./data -o devices.json
+  tomclient report -i ./data -o report.xlsx --format xlsx
+  tomclient report --format=prometheus --textfile-dir=/var/lib/node_exporter/textfile_collector`,
 	Run: func(cmd *cobra.Command, args []string) {
-		err := internal.GenerateInventoryReport(reportInputDir)
+		format := internal.ReportFormat(reportFormat)
+		outputFile := reportOutputFile
+
+		if reportTextfileDir != "" {
+			format = internal.ReportFormatPrometheus
+			outputFile = filepath.Join(reportTextfileDir, "tom_inventory.prom")
+		}
+
+		err := internal.GenerateInventoryReport(reportInputDir, outputFile, format)
 		handleError(err)
-		
-		fmt.Printf("Inventory report generated: %s\n", reportOutputFile)
+
+		fmt.Printf("Inventory report generated: %s\n", outputFile)
 	},
 }
 
@@ -32,5 +51,7 @@ func init() {
 
 	// POSIX-style flags with both long and short versions
 	reportCmd.Flags().StringVarP(&reportInputDir, "input-dir", "i", "inventory", "Directory containing inventory files")
-	reportCmd.Flags().StringVarP(&reportOutputFile, "output", "o", "inventory_report.csv", "Output CSV file name")
-}
\ No newline at end of file
+	reportCmd.Flags().StringVarP(&reportOutputFile, "output", "o", "inventory_report.csv", "Output report file name")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "", "Report format: csv, json, xlsx, markdown, prometheus (default: inferred from --output's extension)")
+	reportCmd.Flags().StringVar(&reportTextfileDir, "textfile-dir", "", "Write a node_exporter textfile collector file (tom_inventory.prom) into this directory instead of --output; implies --format=prometheus")
+}
@@ -6,14 +6,19 @@ import (
 
 	"github.com/spf13/cobra"
 	"tomclient/auth"
+	"tomclient/internal"
+	"tomclient/output"
 	"tomclient/tomapi"
 )
 
 var (
-	apiURL    string
-	configDir string
-	client    *tomapi.Client
-	Version   = "1.0.1"
+	apiURL       string
+	configDir    string
+	socketPath   string
+	profile      string
+	outputFormat string
+	client       *tomapi.Client
+	Version      = "1.0.1"
 )
 
 var rootCmd = &cobra.Command{
@@ -33,7 +38,7 @@ Features:
 			return
 		}
 
-		cfg, err := auth.LoadConfig(configDir)
+		cfg, err := auth.LoadConfigWithProfile(configDir, profile)
 		if err != nil {
 			fmt.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
@@ -44,12 +49,28 @@ Features:
 			os.Exit(1)
 		}
 
+		if err := internal.LoadSerialDecoders(cfg.ConfigDir); err != nil {
+			fmt.Printf("Error loading serial decoders: %v\n", err)
+			os.Exit(1)
+		}
+
 		finalAPIURL := apiURL
 		if apiURL == getDefaultAPIURL() && cfg.APIURL != "" {
 			finalAPIURL = cfg.APIURL
 		}
 
-		client = tomapi.NewClient(finalAPIURL, cfg)
+		finalSocketPath := socketPath
+		if finalSocketPath == "" && cfg.TomSocketPath != "" {
+			finalSocketPath = cfg.TomSocketPath
+		}
+
+		authProvider := auth.NewCLIAuthProvider(cfg)
+
+		if finalSocketPath != "" {
+			client = tomapi.NewClientWithUnixSocket(finalSocketPath, authProvider)
+		} else {
+			client = tomapi.NewClient(finalAPIURL, authProvider)
+		}
 	},
 }
 
@@ -63,6 +84,22 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&apiURL, "api-url", "a", getDefaultAPIURL(), "Tom API server URL")
 	rootCmd.PersistentFlags().StringVar(&configDir, "config-dir", auth.GetConfigDir(), "Config directory path")
+	rootCmd.PersistentFlags().StringVar(&socketPath, "socket", os.Getenv("TOM_API_SOCKET"), "Unix domain socket path (overrides --api-url when set)")
+	rootCmd.PersistentFlags().StringVarP(&profile, "profile", "p", "", "Configuration profile to use for this invocation (overrides TOM_PROFILE and config.json's active profile)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format: table, json, yaml, csv (commands with their own --output/-o, like 'export' and 'grep', use theirs instead)")
+}
+
+// getOutputFormat parses the global --output flag, exiting with an error
+// message on an invalid value rather than returning one, so every command
+// using it can call this unconditionally at the top of Run without its own
+// error plumbing.
+func getOutputFormat() output.Format {
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return format
 }
 
 func getDefaultAPIURL() string {
@@ -78,7 +115,3 @@ func handleError(err error) {
 		os.Exit(1)
 	}
 }
-
-func createClient(apiURL string, cfg *auth.Config) *tomapi.Client {
-	return tomapi.NewClient(apiURL, cfg)
-}
@@ -0,0 +1,236 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AgeDecoder extracts the manufacture year encoded in a vendor-specific
+// serial number format.
+type AgeDecoder interface {
+	// Match reports whether this decoder recognizes serial's format.
+	Match(serial string) bool
+	// Decode extracts the manufacture year encoded in serial.
+	Decode(serial string) (manufactureYear int, err error)
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   []AgeDecoder
+)
+
+// RegisterDecoder adds a decoder to the registry. Decoders are tried in
+// registration order by DecodeAge, so more specific formats should be
+// registered before more permissive ones.
+func RegisterDecoder(d AgeDecoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders = append(decoders, d)
+}
+
+func init() {
+	// Registered most-specific-prefix first so vendor-specific formats are
+	// tried before Cisco's permissive any-3-letter-prefix fallback.
+	RegisterDecoder(aristaAgeDecoder{})
+	RegisterDecoder(juniperAgeDecoder{})
+	RegisterDecoder(ciscoAgeDecoder{})
+}
+
+// DecodeAge returns the manufacture year for serial using the first
+// registered decoder that recognizes its format. ok is false when no
+// decoder recognizes the format, distinguishing "unknown format" from a
+// genuinely ancient device.
+func DecodeAge(serial string) (manufactureYear int, ok bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	for _, d := range decoders {
+		if !d.Match(serial) {
+			continue
+		}
+		year, err := d.Decode(serial)
+		if err != nil {
+			continue
+		}
+		return year, true
+	}
+	return 0, false
+}
+
+// CalculateAge returns a device's age in years based on its serial number,
+// using whichever registered AgeDecoder recognizes its format. ok is false
+// when no decoder recognizes the serial, distinguishing "unknown format"
+// from "0 years old".
+func CalculateAge(serialNumber string) (age int, ok bool) {
+	manufactureYear, ok := DecodeAge(serialNumber)
+	if !ok {
+		return 0, false
+	}
+	return time.Now().Year() - manufactureYear, true
+}
+
+// CalculateAverageAge calculates the average age from a list of serial
+// numbers, skipping any serial whose format isn't recognized by a decoder.
+func CalculateAverageAge(serials []string) float64 {
+	totalAge := 0
+	validSerials := 0
+
+	for _, serial := range serials {
+		if age, ok := CalculateAge(serial); ok {
+			totalAge += age
+			validSerials++
+		}
+	}
+
+	if validSerials == 0 {
+		return 0
+	}
+
+	return float64(totalAge) / float64(validSerials)
+}
+
+// ciscoAgeDecoder decodes Cisco's LLLYYWWSSSS serial format: a 3-letter
+// prefix, a 2-digit year, a 2-digit week, and an alphanumeric sequence.
+type ciscoAgeDecoder struct{}
+
+var ciscoSerialRegex = regexp.MustCompile(`^[A-Z]{3}([0-9]{2})[0-9]{2}[A-Z0-9]{4,}$`)
+
+func (ciscoAgeDecoder) Match(serial string) bool {
+	return ciscoSerialRegex.MatchString(serial)
+}
+
+func (ciscoAgeDecoder) Decode(serial string) (int, error) {
+	matches := ciscoSerialRegex.FindStringSubmatch(serial)
+	if matches == nil {
+		return 0, fmt.Errorf("serial %q does not match Cisco format", serial)
+	}
+	yearCode, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid Cisco year code in %q: %w", serial, err)
+	}
+	return 2000 + yearCode, nil
+}
+
+// juniperAgeDecoder decodes Juniper's "JN"-prefixed serial format: a literal
+// "JN" prefix, a 2-digit year, a 2-digit week, and an alphanumeric sequence.
+type juniperAgeDecoder struct{}
+
+var juniperSerialRegex = regexp.MustCompile(`^JN([0-9]{2})[0-9]{2}[A-Z0-9]{4,}$`)
+
+func (juniperAgeDecoder) Match(serial string) bool {
+	return juniperSerialRegex.MatchString(serial)
+}
+
+func (juniperAgeDecoder) Decode(serial string) (int, error) {
+	matches := juniperSerialRegex.FindStringSubmatch(serial)
+	if matches == nil {
+		return 0, fmt.Errorf("serial %q does not match Juniper format", serial)
+	}
+	yearCode, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid Juniper year code in %q: %w", serial, err)
+	}
+	return 2000 + yearCode, nil
+}
+
+// aristaAgeDecoder decodes Arista's "SSJ"-prefixed serial format: a literal
+// "SSJ" prefix, a 2-digit year, a 2-digit week, and a numeric sequence.
+type aristaAgeDecoder struct{}
+
+var aristaSerialRegex = regexp.MustCompile(`^SSJ([0-9]{2})[0-9]{2}[0-9]{4,}$`)
+
+func (aristaAgeDecoder) Match(serial string) bool {
+	return aristaSerialRegex.MatchString(serial)
+}
+
+func (aristaAgeDecoder) Decode(serial string) (int, error) {
+	matches := aristaSerialRegex.FindStringSubmatch(serial)
+	if matches == nil {
+		return 0, fmt.Errorf("serial %q does not match Arista format", serial)
+	}
+	yearCode, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid Arista year code in %q: %w", serial, err)
+	}
+	return 2000 + yearCode, nil
+}
+
+// SerialDecoderConfig describes a single regex-based decoder loaded from
+// serial_decoders.json, so additional vendors can be supported without
+// recompiling.
+type SerialDecoderConfig struct {
+	Name      string `json:"name"`
+	Pattern   string `json:"pattern"`    // regex with a named capture group holding the 2-digit year
+	YearGroup string `json:"year_group"` // name of the capture group holding the year, e.g. "year"
+	YearBase  int    `json:"year_base"`  // added to the captured year code; defaults to 2000
+}
+
+// regexAgeDecoder implements AgeDecoder from a user-supplied SerialDecoderConfig.
+type regexAgeDecoder struct {
+	cfg *SerialDecoderConfig
+	re  *regexp.Regexp
+}
+
+func (d *regexAgeDecoder) Match(serial string) bool {
+	return d.re.MatchString(serial)
+}
+
+func (d *regexAgeDecoder) Decode(serial string) (int, error) {
+	matches := d.re.FindStringSubmatch(serial)
+	if matches == nil {
+		return 0, fmt.Errorf("serial %q does not match decoder %q", serial, d.cfg.Name)
+	}
+
+	idx := d.re.SubexpIndex(d.cfg.YearGroup)
+	if idx < 0 || idx >= len(matches) {
+		return 0, fmt.Errorf("decoder %q has no capture group named %q", d.cfg.Name, d.cfg.YearGroup)
+	}
+
+	yearCode, err := strconv.Atoi(matches[idx])
+	if err != nil {
+		return 0, fmt.Errorf("invalid year code for decoder %q in %q: %w", d.cfg.Name, serial, err)
+	}
+
+	base := d.cfg.YearBase
+	if base == 0 {
+		base = 2000
+	}
+	return base + yearCode, nil
+}
+
+// LoadSerialDecoders reads serial_decoders.json from configDir, if present,
+// and registers a regex-based decoder for each entry it describes. A missing
+// file is not an error - built-in decoders are used as-is.
+func LoadSerialDecoders(configDir string) error {
+	path := filepath.Join(configDir, "serial_decoders.json")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read serial decoders file: %w", err)
+	}
+
+	var configs []SerialDecoderConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return fmt.Errorf("failed to parse serial decoders file: %w", err)
+	}
+
+	for i := range configs {
+		cfg := configs[i]
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern for decoder %q: %w", cfg.Name, err)
+		}
+		RegisterDecoder(&regexAgeDecoder{cfg: &cfg, re: re})
+	}
+
+	return nil
+}
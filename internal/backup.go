@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"tomclient/tomapi"
+)
+
+// BackupManifestEntry records one device's backed-up config in manifest.json.
+type BackupManifestEntry struct {
+	Device    string    `json:"device"`
+	Timestamp time.Time `json:"timestamp"`
+	SHA256    string    `json:"sha256"`
+	Bytes     int       `json:"bytes"`
+}
+
+// BackupDiffSummary tallies how a new backup compares to a previous one.
+type BackupDiffSummary struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// FetchDeviceConfigs pulls 'show running-config' from every device
+// concurrently, mirroring the worker-pool pattern used by BulkInventory and
+// queryDevicesParallel. A device that fails is reported in errs instead of
+// configs, so one bad device doesn't abort the whole backup.
+func FetchDeviceConfigs(client *tomapi.Client, devices []string, concurrency int) (configs map[string]string, errs map[string]error) {
+	configs = make(map[string]string, len(devices))
+	errs = make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+	sem := make(chan struct{}, concurrency)
+
+	for _, device := range devices {
+		wg.Add(1)
+		go func(dev string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			output, err := client.SendDeviceCommand(dev, "show running-config", true, true, true, nil, false)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[dev] = err
+				return
+			}
+			configs[dev] = output
+		}(device)
+	}
+
+	wg.Wait()
+	return configs, errs
+}
+
+// WriteBackup persists every device's config plus a manifest.json recording
+// each file's timestamp, sha256, and byte length. format is "plain" (a plain
+// directory of <device>.cfg files, outDir naming the directory), "tar", or
+// "tar.gz" (outDir names the resulting archive file instead).
+func WriteBackup(outDir, format string, configs map[string]string) ([]BackupManifestEntry, error) {
+	devices := make([]string, 0, len(configs))
+	for device := range configs {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+
+	now := time.Now()
+	manifest := make([]BackupManifestEntry, 0, len(devices))
+	for _, device := range devices {
+		data := []byte(configs[device])
+		sum := sha256.Sum256(data)
+		manifest = append(manifest, BackupManifestEntry{
+			Device:    device,
+			Timestamp: now,
+			SHA256:    hex.EncodeToString(sum[:]),
+			Bytes:     len(data),
+		})
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	switch format {
+	case "", "plain":
+		err = writeBackupPlain(outDir, devices, configs, manifestJSON)
+	case "tar":
+		err = writeBackupTar(outDir, devices, configs, manifestJSON, false)
+	case "tar.gz":
+		err = writeBackupTar(outDir, devices, configs, manifestJSON, true)
+	default:
+		return nil, fmt.Errorf("invalid backup format: %s (must be one of: plain, tar, tar.gz)", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func writeBackupPlain(outDir string, devices []string, configs map[string]string, manifestJSON []byte) error {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	for _, device := range devices {
+		path := filepath.Join(outDir, device+".cfg")
+		if err := os.WriteFile(path, []byte(configs[device]), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "manifest.json"), manifestJSON, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+func writeBackupTar(outPath string, devices []string, configs map[string]string, manifestJSON []byte, gzipped bool) error {
+	if dir := filepath.Dir(outPath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create backup directory: %w", err)
+		}
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive %s: %w", outPath, err)
+	}
+	defer f.Close()
+
+	var tw *tar.Writer
+	if gzipped {
+		gw := gzip.NewWriter(f)
+		defer gw.Close()
+		tw = tar.NewWriter(gw)
+	} else {
+		tw = tar.NewWriter(f)
+	}
+	defer tw.Close()
+
+	writeEntry := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	for _, device := range devices {
+		if err := writeEntry(device+".cfg", []byte(configs[device])); err != nil {
+			return fmt.Errorf("failed to write %s to archive: %w", device, err)
+		}
+	}
+	if err := writeEntry("manifest.json", manifestJSON); err != nil {
+		return fmt.Errorf("failed to write manifest to archive: %w", err)
+	}
+	return nil
+}
+
+// DiffBackups compares a freshly-fetched set of device configs against a
+// previous plain-format backup directory, printing a unified diff for every
+// device whose config changed. It returns a summary of devices added,
+// removed, and changed relative to the previous backup.
+func DiffBackups(prevDir, newDir string, devices []string, configs map[string]string) (*BackupDiffSummary, error) {
+	prevManifest, err := loadManifest(prevDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read previous backup manifest: %w", err)
+	}
+
+	prevDevices := make(map[string]bool, len(prevManifest))
+	for _, entry := range prevManifest {
+		prevDevices[entry.Device] = true
+	}
+
+	summary := &BackupDiffSummary{}
+	for _, device := range devices {
+		if !prevDevices[device] {
+			summary.Added = append(summary.Added, device)
+			continue
+		}
+		delete(prevDevices, device)
+
+		prevData, err := os.ReadFile(filepath.Join(prevDir, device+".cfg"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read previous config for %s: %w", device, err)
+		}
+
+		if string(prevData) == configs[device] {
+			continue
+		}
+		summary.Changed = append(summary.Changed, device)
+
+		diff := difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(prevData)),
+			B:        difflib.SplitLines(configs[device]),
+			FromFile: filepath.Join(prevDir, device+".cfg"),
+			ToFile:   filepath.Join(newDir, device+".cfg"),
+			Context:  3,
+		}
+		text, err := difflib.GetUnifiedDiffString(diff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", device, err)
+		}
+		fmt.Print(text)
+	}
+
+	for device := range prevDevices {
+		summary.Removed = append(summary.Removed, device)
+	}
+	sort.Strings(summary.Added)
+	sort.Strings(summary.Removed)
+	sort.Strings(summary.Changed)
+
+	return summary, nil
+}
+
+func loadManifest(dir string) ([]BackupManifestEntry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, err
+	}
+	var manifest []BackupManifestEntry
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return manifest, nil
+}
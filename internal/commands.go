@@ -1,77 +1,103 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
-	"sync"
 
 	"tomclient/tomapi"
 )
 
-// BulkInventory processes devices for inventory collection
-func BulkInventory(client *tomapi.Client, devicesFile string, concurrency int) error {
+// BulkInventory processes devices for inventory collection. retry governs how
+// hard each device's query retries - with exponential backoff and jitter -
+// on a transient error before giving up, and ctx lets a caller cancel the
+// whole batch (e.g. on SIGINT) without losing results already written. When
+// textfileDir is non-empty, a tom_collection_errors_total counter (one
+// sample per device that ultimately failed) is written there for
+// node_exporter's textfile collector, alongside report's
+// tom_device_component_age_years family.
+func BulkInventory(ctx context.Context, client *tomapi.Client, devicesFile string, concurrency int, retry RetryOptions, textfileDir string) error {
 	data, err := os.ReadFile(devicesFile)
 	if err != nil {
 		return fmt.Errorf("error reading devices file: %w", err)
 	}
-	
+
 	var devices map[string]any
 	err = json.Unmarshal(data, &devices)
 	if err != nil {
 		return fmt.Errorf("error parsing devices JSON: %w", err)
 	}
-	
+
 	err = os.MkdirAll("inventory", 0755)
 	if err != nil {
 		return fmt.Errorf("error creating inventory directory: %w", err)
 	}
-	
+
 	hostnames := make([]string, 0, len(devices))
 	for hostname := range devices {
 		hostnames = append(hostnames, hostname)
 	}
-	
+
 	fmt.Printf("Processing %d devices with %d concurrent workers...\n", len(hostnames), concurrency)
-	
-	sem := make(chan struct{}, concurrency)
-	var wg sync.WaitGroup
-	
-	for _, hostname := range hostnames {
-		wg.Add(1)
-		go func(h string) {
-			sem <- struct{}{}
-			processDevice(client, h, &wg)
-			<-sem
-		}(hostname)
+
+	retry.RetryOn = tomapi.IsRetryableError
+	results := RunConcurrent(ctx, hostnames, concurrency, retry, func(ctx context.Context, hostname string) error {
+		return fetchDeviceInventory(client, hostname)
+	})
+
+	var retried, failed int
+	var errorSamples []MetricSample
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed++
+			fmt.Printf("Error fetching inventory for %s (after %d attempts): %v\n", r.Item, r.Attempts, r.Err)
+			errorSamples = append(errorSamples, MetricSample{
+				Labels: map[string]string{"hostname": r.Item},
+				Value:  1,
+			})
+		case r.Attempts > 1:
+			retried++
+			fmt.Printf("Fetched inventory for %s after %d attempts\n", r.Item, r.Attempts)
+		}
 	}
-	
-	wg.Wait()
-	fmt.Println("All devices processed.")
+
+	fmt.Printf("All devices processed (%d retried, %d failed).\n", retried, failed)
+
+	if textfileDir != "" {
+		families := []MetricFamily{{
+			Name:    "tom_collection_errors_total",
+			Help:    "Devices that failed inventory collection after all retries",
+			Type:    "counter",
+			Samples: errorSamples,
+		}}
+		path := filepath.Join(textfileDir, "tom_bulk_inventory.prom")
+		if err := WriteTextfile(path, families); err != nil {
+			return fmt.Errorf("error writing collection error metrics: %w", err)
+		}
+	}
+
 	return nil
 }
 
-func processDevice(client *tomapi.Client, hostname string, wg *sync.WaitGroup) {
-	defer wg.Done()
-	
+func fetchDeviceInventory(client *tomapi.Client, hostname string) error {
 	fmt.Printf("Fetching inventory for %s...\n", hostname)
-	
-	result, err := client.SendDeviceCommand(hostname, "show inventory | i ASR", true, true)
+
+	result, err := client.SendDeviceCommand(hostname, "show inventory | i ASR", true, true, false, nil, false)
 	if err != nil {
-		fmt.Printf("Error fetching inventory for %s: %v\n", hostname, err)
-		return
+		return err
 	}
-	
+
 	filename := filepath.Join("inventory", hostname+"_inventory.txt")
-	err = os.WriteFile(filename, []byte(result), 0644)
-	if err != nil {
-		fmt.Printf("Error writing file for %s: %v\n", hostname, err)
-		return
+	if err := os.WriteFile(filename, []byte(result), 0644); err != nil {
+		return fmt.Errorf("error writing file for %s: %w", hostname, err)
 	}
-	
+
 	fmt.Printf("Saved inventory for %s to %s\n", hostname, filename)
+	return nil
 }
 
 // ParseConcurrency parses and validates concurrency argument
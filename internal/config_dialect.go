@@ -0,0 +1,361 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ConfigDialect abstracts the vendor-specific syntax needed to pull
+// interface blocks out of a device config and to generate commands that
+// delete or reset a given interface. ParseInterfaceConfig picks a dialect
+// automatically (see DetectDialect); FindSSNInterfaces and the delete-command
+// generators then work the same way regardless of which one matched.
+type ConfigDialect interface {
+	// Name identifies the dialect, e.g. "ios", "nxos", "junos", "eos", "sros".
+	Name() string
+
+	// Matches reports whether this dialect recognizes filename or sample (the
+	// first handful of lines of the config, blank ones included) as its own
+	// format.
+	Matches(filename string, sample []string) bool
+
+	// ParseInterfaces extracts every interface block from a full config.
+	ParseInterfaces(lines []string) []InterfaceInfo
+
+	// DeleteCommands returns the command(s) that delete or reset interfaceName,
+	// assuming the session is already inside whatever context EnterConfig left
+	// it in.
+	DeleteCommands(interfaceName string) []string
+
+	// EnterConfig returns the command(s) needed to reach the context
+	// DeleteCommands expects, run once before any interface is deleted.
+	EnterConfig() []string
+
+	// ExitConfig returns the command(s) that leave config mode and persist
+	// the change, run once after every interface has been deleted.
+	ExitConfig() []string
+}
+
+var (
+	dialectRegistry = map[string]ConfigDialect{}
+	dialectOrder    []string
+)
+
+// RegisterDialect adds a ConfigDialect to the registry under name (used for
+// lookup and, in registration order, for auto-detection priority). Dialects
+// registered later take priority over earlier ones with the same name.
+func RegisterDialect(name string, d ConfigDialect) {
+	key := strings.ToLower(name)
+	if _, exists := dialectRegistry[key]; !exists {
+		dialectOrder = append(dialectOrder, key)
+	}
+	dialectRegistry[key] = d
+}
+
+// Dialect looks up a registered ConfigDialect by name.
+func Dialect(name string) (ConfigDialect, bool) {
+	d, ok := dialectRegistry[strings.ToLower(name)]
+	return d, ok
+}
+
+// DetectDialect picks the ConfigDialect whose Matches reports true first, in
+// registration order, falling back to "ios" (the most common format in this
+// repo's config collections) if nothing else claims it.
+func DetectDialect(filename string, sample []string) ConfigDialect {
+	for _, name := range dialectOrder {
+		if name == "ios" {
+			continue // ios is the fallback, tried last
+		}
+		if d := dialectRegistry[name]; d.Matches(filename, sample) {
+			return d
+		}
+	}
+	return dialectRegistry["ios"]
+}
+
+func init() {
+	RegisterDialect("junos", junosDialect{})
+	RegisterDialect("sros", srosDialect{})
+	RegisterDialect("nxos", nxosDialect{})
+	RegisterDialect("eos", eosDialect{})
+	RegisterDialect("ios", iosDialect{})
+}
+
+// blockDescriptionPattern and parseBlockInterfaces implement the IOS-style
+// "interface X" / indented "description ..." block layout shared by Cisco
+// IOS/IOS-XE, Cisco NX-OS, and Arista EOS configs (interfacePattern varies
+// slightly by dialect, but the block structure is identical).
+var blockDescriptionPattern = regexp.MustCompile(`^\s*description\s+(.+)$`)
+
+func parseBlockInterfaces(lines []string, interfacePattern *regexp.Regexp) []InterfaceInfo {
+	var interfaces []InterfaceInfo
+	var current *InterfaceInfo
+
+	for _, line := range lines {
+		if matches := interfacePattern.FindStringSubmatch(line); matches != nil {
+			if current != nil {
+				interfaces = append(interfaces, *current)
+			}
+			current = &InterfaceInfo{Name: matches[1], Config: []string{line}}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		current.Config = append(current.Config, line)
+		if matches := blockDescriptionPattern.FindStringSubmatch(line); matches != nil {
+			current.Description = matches[1]
+		}
+	}
+
+	if current != nil {
+		interfaces = append(interfaces, *current)
+	}
+
+	return interfaces
+}
+
+// iosDialect covers Cisco IOS and IOS-XE, the original hardcoded format this
+// package supported before other vendors were added.
+type iosDialect struct{}
+
+func (iosDialect) Name() string { return "ios" }
+
+func (iosDialect) Matches(filename string, sample []string) bool {
+	return true // fallback dialect, always matches
+}
+
+var iosInterfacePattern = regexp.MustCompile(`^interface\s+(.+)$`)
+
+func (iosDialect) ParseInterfaces(lines []string) []InterfaceInfo {
+	return parseBlockInterfaces(lines, iosInterfacePattern)
+}
+
+func (iosDialect) DeleteCommands(interfaceName string) []string {
+	if isSubInterface(interfaceName) {
+		return []string{"no interface " + interfaceName}
+	}
+	return []string{"default interface " + interfaceName}
+}
+
+func (iosDialect) EnterConfig() []string { return []string{"configure terminal"} }
+func (iosDialect) ExitConfig() []string  { return []string{"exit", "write memory"} }
+
+// nxosDialect covers Cisco NX-OS. Interface blocks and description lines are
+// written the same way as IOS, but NX-OS interfaces (physical or logical) are
+// conventionally removed with "no interface" rather than reset via "default
+// interface".
+type nxosDialect struct{}
+
+func (nxosDialect) Name() string { return "nxos" }
+
+func (nxosDialect) Matches(filename string, sample []string) bool {
+	lower := strings.ToLower(filename)
+	if strings.Contains(lower, "nxos") || strings.Contains(lower, "nx-os") {
+		return true
+	}
+	for _, line := range sample {
+		if strings.Contains(line, "NX-OS") {
+			return true
+		}
+	}
+	return false
+}
+
+func (nxosDialect) ParseInterfaces(lines []string) []InterfaceInfo {
+	return parseBlockInterfaces(lines, iosInterfacePattern)
+}
+
+func (nxosDialect) DeleteCommands(interfaceName string) []string {
+	return []string{"no interface " + interfaceName}
+}
+
+func (nxosDialect) EnterConfig() []string { return []string{"configure terminal"} }
+func (nxosDialect) ExitConfig() []string  { return []string{"exit", "copy running-config startup-config"} }
+
+// eosDialect covers Arista EOS, which shares IOS's "interface X" / indented
+// "description ..." block syntax and its "default interface"/"no interface"
+// deletion commands.
+type eosDialect struct{}
+
+func (eosDialect) Name() string { return "eos" }
+
+func (eosDialect) Matches(filename string, sample []string) bool {
+	lower := strings.ToLower(filename)
+	if strings.Contains(lower, "eos") || strings.Contains(lower, "arista") {
+		return true
+	}
+	for _, line := range sample {
+		if strings.Contains(line, "Arista") {
+			return true
+		}
+	}
+	return false
+}
+
+func (eosDialect) ParseInterfaces(lines []string) []InterfaceInfo {
+	return parseBlockInterfaces(lines, iosInterfacePattern)
+}
+
+func (eosDialect) DeleteCommands(interfaceName string) []string {
+	if isSubInterface(interfaceName) {
+		return []string{"no interface " + interfaceName}
+	}
+	return []string{"default interface " + interfaceName}
+}
+
+func (eosDialect) EnterConfig() []string { return []string{"configure terminal"} }
+func (eosDialect) ExitConfig() []string  { return []string{"exit", "write memory"} }
+
+// junosDialect covers Juniper JunOS "set" format, a flat list of
+// "set interfaces <name> [unit <n>] ..." lines rather than indented blocks.
+// A physical interface and its units are folded into one InterfaceInfo per
+// unit, named "<interface>.<unit>" to match the dotted sub-interface naming
+// convention used elsewhere in this package.
+type junosDialect struct{}
+
+func (junosDialect) Name() string { return "junos" }
+
+func (junosDialect) Matches(filename string, sample []string) bool {
+	lower := strings.ToLower(filename)
+	if strings.Contains(lower, "junos") || strings.Contains(lower, "juniper") {
+		return true
+	}
+	for _, line := range sample {
+		if strings.HasPrefix(strings.TrimSpace(line), "set interfaces ") {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	junosUnitPattern  = regexp.MustCompile(`^set interfaces (\S+) unit (\d+)(?:\s+description\s+"?([^"]*)"?)?`)
+	junosIfacePattern = regexp.MustCompile(`^set interfaces (\S+)(?:\s+description\s+"?([^"]*)"?)?$`)
+)
+
+func (junosDialect) ParseInterfaces(lines []string) []InterfaceInfo {
+	index := make(map[string]int)
+	var interfaces []InterfaceInfo
+
+	add := func(name, description, line string) {
+		if i, ok := index[name]; ok {
+			interfaces[i].Config = append(interfaces[i].Config, line)
+			if description != "" {
+				interfaces[i].Description = description
+			}
+			return
+		}
+		index[name] = len(interfaces)
+		interfaces = append(interfaces, InterfaceInfo{
+			Name:        name,
+			Description: description,
+			Config:      []string{line},
+		})
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "set interfaces ") {
+			continue
+		}
+
+		if matches := junosUnitPattern.FindStringSubmatch(trimmed); matches != nil {
+			add(matches[1]+"."+matches[2], matches[3], line)
+			continue
+		}
+		if matches := junosIfacePattern.FindStringSubmatch(trimmed); matches != nil {
+			add(matches[1], matches[2], line)
+		}
+	}
+
+	return interfaces
+}
+
+func (junosDialect) DeleteCommands(interfaceName string) []string {
+	if idx := strings.LastIndex(interfaceName, "."); idx >= 0 {
+		return []string{"delete interfaces " + interfaceName[:idx] + " unit " + interfaceName[idx+1:]}
+	}
+	return []string{"delete interfaces " + interfaceName}
+}
+
+func (junosDialect) EnterConfig() []string { return []string{"configure"} }
+func (junosDialect) ExitConfig() []string  { return []string{"commit", "exit"} }
+
+// srosDialect covers Nokia SR OS classic CLI, where interfaces live inside a
+// "configure router" context block: `interface "name" ... description "..."
+// ... exit`. Unlike the Cisco/Arista family, deleting an interface requires
+// re-entering that context rather than a single top-level command.
+type srosDialect struct{}
+
+func (srosDialect) Name() string { return "sros" }
+
+func (srosDialect) Matches(filename string, sample []string) bool {
+	lower := strings.ToLower(filename)
+	if strings.Contains(lower, "sros") || strings.Contains(lower, "nokia") {
+		return true
+	}
+	for _, line := range sample {
+		if strings.Contains(line, "TiMOS") {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	srosInterfacePattern   = regexp.MustCompile(`^\s*interface\s+"([^"]+)"`)
+	srosDescriptionPattern = regexp.MustCompile(`^\s*description\s+"([^"]*)"`)
+)
+
+func (srosDialect) ParseInterfaces(lines []string) []InterfaceInfo {
+	var interfaces []InterfaceInfo
+	var current *InterfaceInfo
+	var depth int
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if matches := srosInterfacePattern.FindStringSubmatch(line); matches != nil {
+			if current != nil {
+				interfaces = append(interfaces, *current)
+			}
+			current = &InterfaceInfo{Name: matches[1], Config: []string{line}}
+			depth = 1
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		current.Config = append(current.Config, line)
+		if matches := srosDescriptionPattern.FindStringSubmatch(line); matches != nil {
+			current.Description = matches[1]
+		}
+
+		switch trimmed {
+		case "exit", "exit all":
+			depth--
+			if depth <= 0 {
+				interfaces = append(interfaces, *current)
+				current = nil
+			}
+		}
+	}
+
+	if current != nil {
+		interfaces = append(interfaces, *current)
+	}
+
+	return interfaces
+}
+
+func (srosDialect) DeleteCommands(interfaceName string) []string {
+	return []string{"no interface \"" + interfaceName + "\""}
+}
+
+func (srosDialect) EnterConfig() []string { return []string{"configure", "router"} }
+func (srosDialect) ExitConfig() []string  { return []string{"exit all"} }
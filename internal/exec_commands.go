@@ -0,0 +1,326 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tomclient/tomapi"
+)
+
+// deletionFileSuffix matches the files written by parse-interfaces.
+const deletionFileSuffix = "_delete_ssn_interfaces.txt"
+
+// DeviceCommands is a single device's parsed deletion command file.
+type DeviceCommands struct {
+	Hostname string
+	Commands []string
+}
+
+// ExecResult is the structured per-device outcome written to
+// <ResultsDir>/<hostname>.json, so a later run can resume from where a
+// previous one left off.
+type ExecResult struct {
+	Hostname  string    `json:"hostname"`
+	Commands  []string  `json:"commands"`
+	DryRun    bool      `json:"dry_run"`
+	Success   bool      `json:"success"`
+	JobID     string    `json:"job_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	DurationMS int64    `json:"duration_ms"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ExecCommandsOptions configures ExecCommands' staged rollout, rate limiting,
+// and circuit-breaking behavior.
+type ExecCommandsOptions struct {
+	InputDir       string
+	ResultsDir     string
+	Concurrency    int
+	Canary         int // run the first N devices, then pause for confirmation
+	DryRun         bool
+	RPS            float64 // 0 disables rate limiting
+	MaxFailures    int     // abort after this many total failures (0 disables)
+	MaxConsecutive int     // abort after this many consecutive failures (0 disables)
+	Resume         bool
+	Confirm        func() bool // prompts to continue past the canary batch; defaults to stdin
+}
+
+// LoadDeletionCommands reads every *_delete_ssn_interfaces.txt file in dir,
+// stripping the "!"-prefixed comment lines that internal.GenerateDeleteCommandsDetailed
+// intersperses with real CLI commands.
+func LoadDeletionCommands(dir string) ([]DeviceCommands, error) {
+	pattern := filepath.Join(dir, "*"+deletionFileSuffix)
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("error globbing %s: %w", pattern, err)
+	}
+
+	devices := make([]DeviceCommands, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+
+		hostname := strings.TrimSuffix(filepath.Base(file), deletionFileSuffix)
+
+		var commands []string
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimRight(line, "\r")
+			if line == "" || strings.HasPrefix(line, "!") {
+				continue
+			}
+			commands = append(commands, line)
+		}
+
+		if len(commands) == 0 {
+			continue
+		}
+
+		devices = append(devices, DeviceCommands{Hostname: hostname, Commands: commands})
+	}
+
+	return devices, nil
+}
+
+// loadCompletedDevices reads previously written ExecResult files out of
+// resultsDir and returns the set of hostnames already marked as succeeded on
+// a real (non-dry-run) attempt - a dry-run success doesn't mean the device
+// ever actually received its deletion commands, so --resume must not treat
+// it as done.
+func loadCompletedDevices(resultsDir string) (map[string]bool, error) {
+	completed := make(map[string]bool)
+
+	files, err := filepath.Glob(filepath.Join(resultsDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error globbing %s: %w", resultsDir, err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var result ExecResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			continue
+		}
+		if result.Success && !result.DryRun {
+			completed[result.Hostname] = true
+		}
+	}
+
+	return completed, nil
+}
+
+func saveExecResult(resultsDir string, result ExecResult) error {
+	if err := os.MkdirAll(resultsDir, 0755); err != nil {
+		return fmt.Errorf("error creating results directory: %w", err)
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling result: %w", err)
+	}
+	path := filepath.Join(resultsDir, result.Hostname+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// execDevice sends a single device's commands through tomapi and returns the
+// structured result. In dry-run mode it never contacts the API - it just
+// prints the commands that would be sent, preserving the "review before
+// execute" posture that parse-interfaces established.
+func execDevice(client *tomapi.Client, device DeviceCommands, dryRun bool) ExecResult {
+	start := time.Now()
+	result := ExecResult{
+		Hostname:  device.Hostname,
+		Commands:  device.Commands,
+		DryRun:    dryRun,
+		Timestamp: start,
+	}
+
+	if dryRun {
+		fmt.Printf("=== %s (dry-run, %d commands) ===\n", device.Hostname, len(device.Commands))
+		for _, c := range device.Commands {
+			fmt.Println("  " + c)
+		}
+		result.Success = true
+		result.DurationMS = time.Since(start).Milliseconds()
+		return result
+	}
+
+	cmds := make([]interface{}, len(device.Commands))
+	for i, c := range device.Commands {
+		cmds[i] = c
+	}
+
+	job, err := client.SendCommands(device.Hostname, tomapi.SendCommandsRequest{
+		Commands: cmds,
+		Wait:     true,
+	})
+	result.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Success = false
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Success = true
+	result.JobID = job.ID
+	return result
+}
+
+// confirmContinue asks on stdin whether to proceed past a canary batch.
+func confirmContinue() bool {
+	fmt.Print("Canary batch complete. Continue with remaining devices? [y/N]: ")
+	var resp string
+	fmt.Scanln(&resp)
+	return strings.EqualFold(strings.TrimSpace(resp), "y")
+}
+
+// ExecCommands pushes each device's deletion command file through tomapi,
+// reusing the same semaphore-bounded concurrency harness as BulkInventory. It
+// supports a canary stage that runs sequentially and pauses for confirmation,
+// a token-bucket rate limiter, and a circuit breaker that aborts the batch
+// once too many devices fail. Results are logged per-device to ResultsDir so
+// a re-run with Resume can skip devices already marked succeeded.
+func ExecCommands(client *tomapi.Client, opts ExecCommandsOptions) error {
+	devices, err := LoadDeletionCommands(opts.InputDir)
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		fmt.Printf("No deletion command files found in %s\n", opts.InputDir)
+		return nil
+	}
+
+	if opts.Resume {
+		completed, err := loadCompletedDevices(opts.ResultsDir)
+		if err != nil {
+			return err
+		}
+		remaining := devices[:0]
+		for _, d := range devices {
+			if completed[d.Hostname] {
+				fmt.Printf("Skipping %s (already succeeded)\n", d.Hostname)
+				continue
+			}
+			remaining = append(remaining, d)
+		}
+		devices = remaining
+	}
+
+	if len(devices) == 0 {
+		fmt.Println("Nothing to do.")
+		return nil
+	}
+
+	canary := opts.Canary
+	if canary > len(devices) {
+		canary = len(devices)
+	}
+
+	if canary > 0 {
+		fmt.Printf("Canary: running %d of %d devices sequentially...\n", canary, len(devices))
+		for _, device := range devices[:canary] {
+			result := execDevice(client, device, opts.DryRun)
+			if err := saveExecResult(opts.ResultsDir, result); err != nil {
+				fmt.Printf("warning: failed to save result for %s: %v\n", device.Hostname, err)
+			}
+			if !result.Success {
+				fmt.Printf("%s: FAILED: %s\n", device.Hostname, result.Error)
+			}
+		}
+
+		confirm := opts.Confirm
+		if confirm == nil {
+			confirm = confirmContinue
+		}
+		if !confirm() {
+			return fmt.Errorf("aborted after canary batch")
+		}
+
+		devices = devices[canary:]
+		if len(devices) == 0 {
+			return nil
+		}
+	}
+
+	return runExecBatch(client, devices, opts)
+}
+
+// runExecBatch runs the remaining devices concurrently, enforcing the rate
+// limit and circuit breaker across all workers.
+func runExecBatch(client *tomapi.Client, devices []DeviceCommands, opts ExecCommandsOptions) error {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 10
+	}
+
+	var limiter *time.Ticker
+	if opts.RPS > 0 {
+		limiter = time.NewTicker(time.Duration(float64(time.Second) / opts.RPS))
+		defer limiter.Stop()
+	}
+
+	var totalFailures, consecutiveFailures int64
+	var aborted int32
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, device := range devices {
+		if atomic.LoadInt32(&aborted) == 1 {
+			break
+		}
+
+		wg.Add(1)
+		go func(d DeviceCommands) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if atomic.LoadInt32(&aborted) == 1 {
+				return
+			}
+			if limiter != nil {
+				<-limiter.C
+			}
+
+			result := execDevice(client, d, opts.DryRun)
+			if err := saveExecResult(opts.ResultsDir, result); err != nil {
+				fmt.Printf("warning: failed to save result for %s: %v\n", d.Hostname, err)
+			}
+
+			if result.Success {
+				atomic.StoreInt64(&consecutiveFailures, 0)
+				return
+			}
+
+			fmt.Printf("%s: FAILED: %s\n", d.Hostname, result.Error)
+			consecutive := atomic.AddInt64(&consecutiveFailures, 1)
+			total := atomic.AddInt64(&totalFailures, 1)
+
+			tripped := (opts.MaxConsecutive > 0 && consecutive >= int64(opts.MaxConsecutive)) ||
+				(opts.MaxFailures > 0 && total >= int64(opts.MaxFailures))
+			if tripped && atomic.CompareAndSwapInt32(&aborted, 0, 1) {
+				fmt.Println("Circuit breaker tripped: aborting remaining devices.")
+			}
+		}(device)
+	}
+
+	wg.Wait()
+
+	if atomic.LoadInt32(&aborted) == 1 {
+		return fmt.Errorf("aborted: failure threshold exceeded")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLoadCompletedDevicesIgnoresDryRun guards against --resume treating a
+// dry-run result as done: a dry-run never actually sent the device's
+// deletion commands, so it must not be skipped on the real run.
+func TestLoadCompletedDevicesIgnoresDryRun(t *testing.T) {
+	dir := t.TempDir()
+
+	results := []ExecResult{
+		{Hostname: "dry-run-router", Success: true, DryRun: true, Timestamp: time.Now()},
+		{Hostname: "real-run-router", Success: true, DryRun: false, Timestamp: time.Now()},
+		{Hostname: "failed-router", Success: false, DryRun: false, Timestamp: time.Now()},
+	}
+
+	for _, r := range results {
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatalf("failed to marshal fixture: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, r.Hostname+".json"), data, 0644); err != nil {
+			t.Fatalf("failed to write fixture: %v", err)
+		}
+	}
+
+	completed, err := loadCompletedDevices(dir)
+	if err != nil {
+		t.Fatalf("loadCompletedDevices returned error: %v", err)
+	}
+
+	if completed["dry-run-router"] {
+		t.Error("dry-run-router was only ever dry-run, should not be marked completed")
+	}
+	if !completed["real-run-router"] {
+		t.Error("real-run-router succeeded for real, should be marked completed")
+	}
+	if completed["failed-router"] {
+		t.Error("failed-router never succeeded, should not be marked completed")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
 )
 
@@ -18,10 +17,14 @@ type InterfaceInfo struct {
 // DeviceInterfaceInfo represents all interfaces for a device
 type DeviceInterfaceInfo struct {
 	Hostname   string
+	Dialect    ConfigDialect
 	Interfaces []InterfaceInfo
 }
 
-// ParseInterfaceConfig parses interface configuration from a file
+// ParseInterfaceConfig parses interface configuration from a file, detecting
+// its vendor dialect (see DetectDialect) from the filename and a sample of
+// its contents so IOS, NX-OS, JunOS, EOS, and SR OS configs are all handled
+// without the caller needing to know which one it's looking at.
 func ParseInterfaceConfig(filename string) (*DeviceInterfaceInfo, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -38,60 +41,27 @@ func ParseInterfaceConfig(filename string) (*DeviceInterfaceInfo, error) {
 		hostname = hostname[idx+1:]
 	}
 
-	deviceInfo := &DeviceInterfaceInfo{
-		Hostname:   hostname,
-		Interfaces: []InterfaceInfo{},
-	}
-
+	var lines []string
 	scanner := bufio.NewScanner(file)
-	var currentInterface *InterfaceInfo
-	
-	// Regex patterns
-	interfacePattern := regexp.MustCompile(`^interface\s+(.+)$`)
-	descriptionPattern := regexp.MustCompile(`^\s*description\s+(.+)$`)
-	
 	for scanner.Scan() {
-		line := scanner.Text()
-		
-		// Check if this is an interface line
-		if matches := interfacePattern.FindStringSubmatch(line); matches != nil {
-			// Save previous interface if exists
-			if currentInterface != nil {
-				deviceInfo.Interfaces = append(deviceInfo.Interfaces, *currentInterface)
-			}
-			
-			// Start new interface
-			currentInterface = &InterfaceInfo{
-				Name:   matches[1],
-				Config: []string{line},
-			}
-		} else if currentInterface != nil {
-			// Add line to current interface config
-			currentInterface.Config = append(currentInterface.Config, line)
-			
-			// Check for description
-			if matches := descriptionPattern.FindStringSubmatch(line); matches != nil {
-				currentInterface.Description = matches[1]
-			}
-			
-			// Check if we're at the end of this interface (next interface or end of config)
-			if strings.HasPrefix(line, "!") || 
-			   (len(strings.TrimSpace(line)) == 0 && len(currentInterface.Config) > 1) {
-				// This might be the end of the interface block
-				continue
-			}
-		}
+		lines = append(lines, scanner.Text())
 	}
-	
-	// Don't forget the last interface
-	if currentInterface != nil {
-		deviceInfo.Interfaces = append(deviceInfo.Interfaces, *currentInterface)
-	}
-
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("error reading file %s: %w", filename, err)
 	}
 
+	sample := lines
+	if len(sample) > 20 {
+		sample = sample[:20]
+	}
+	dialect := DetectDialect(filename, sample)
+
+	deviceInfo := &DeviceInterfaceInfo{
+		Hostname:   hostname,
+		Dialect:    dialect,
+		Interfaces: dialect.ParseInterfaces(lines),
+	}
+
 	return deviceInfo, nil
 }
 
@@ -113,63 +83,52 @@ func isSubInterface(interfaceName string) bool {
 	return strings.Contains(interfaceName, ".")
 }
 
-// GenerateDeleteCommands generates Cisco commands to delete interfaces
-func GenerateDeleteCommands(interfaces []InterfaceInfo) []string {
+// GenerateDeleteCommands generates commands to delete interfaces, in
+// dialect's own syntax.
+func GenerateDeleteCommands(dialect ConfigDialect, interfaces []InterfaceInfo) []string {
 	var commands []string
-	
-	// Add configuration mode entry
-	commands = append(commands, "configure terminal")
-	
+
+	commands = append(commands, dialect.EnterConfig()...)
+
 	for _, iface := range interfaces {
-		// Use different commands based on interface type
-		if isSubInterface(iface.Name) {
-			commands = append(commands, fmt.Sprintf("no interface %s", iface.Name))
-		} else {
-			commands = append(commands, fmt.Sprintf("default interface %s", iface.Name))
-		}
+		commands = append(commands, dialect.DeleteCommands(iface.Name)...)
 	}
-	
-	// Add exit and save commands
-	commands = append(commands, "exit")
-	commands = append(commands, "write memory")
-	
+
+	commands = append(commands, dialect.ExitConfig()...)
+
 	return commands
 }
 
-// GenerateDeleteCommandsDetailed generates detailed deletion commands with confirmation
-func GenerateDeleteCommandsDetailed(interfaces []InterfaceInfo) []string {
+// GenerateDeleteCommandsDetailed generates the same commands as
+// GenerateDeleteCommands, annotated with "!"-prefixed comments explaining
+// what each interface deletion does. The comment style is Cisco/Arista's,
+// used here as a readable review format regardless of dialect - JunOS and SR
+// OS devices won't execute the comment lines, but the commands that follow
+// are still correct for whichever dialect matched.
+func GenerateDeleteCommandsDetailed(dialect ConfigDialect, interfaces []InterfaceInfo) []string {
 	var commands []string
-	
-	// Add header comment
+
 	commands = append(commands, "! Generated interface deletion commands")
 	commands = append(commands, fmt.Sprintf("! Found %d interfaces with SSN in description", len(interfaces)))
+	commands = append(commands, fmt.Sprintf("! Dialect: %s", dialect.Name()))
 	commands = append(commands, "!")
 	commands = append(commands, "! WARNING: These commands will DELETE/RESET interfaces - REVIEW CAREFULLY")
-	commands = append(commands, "! Subinterfaces: 'no interface X.Y' (removes subinterface)")
-	commands = append(commands, "! Physical interfaces: 'default interface X' (resets to factory defaults)")
 	commands = append(commands, "!")
-	
-	// Add configuration mode entry
-	commands = append(commands, "configure terminal")
+
+	commands = append(commands, dialect.EnterConfig()...)
 	commands = append(commands, "!")
-	
+
 	for _, iface := range interfaces {
-		// Add comment showing what we're doing
 		if isSubInterface(iface.Name) {
 			commands = append(commands, fmt.Sprintf("! Removing subinterface %s - Description: %s", iface.Name, iface.Description))
-			commands = append(commands, fmt.Sprintf("no interface %s", iface.Name))
 		} else {
-			commands = append(commands, fmt.Sprintf("! Resetting physical interface %s - Description: %s", iface.Name, iface.Description))
-			commands = append(commands, fmt.Sprintf("default interface %s", iface.Name))
+			commands = append(commands, fmt.Sprintf("! Resetting interface %s - Description: %s", iface.Name, iface.Description))
 		}
+		commands = append(commands, dialect.DeleteCommands(iface.Name)...)
 		commands = append(commands, "!")
 	}
-	
-	// Add exit and save commands
-	commands = append(commands, "exit")
-	commands = append(commands, "!")
-	commands = append(commands, "! Save configuration")
-	commands = append(commands, "write memory")
-	
+
+	commands = append(commands, dialect.ExitConfig()...)
+
 	return commands
 }
\ No newline at end of file
@@ -0,0 +1,51 @@
+package internal
+
+import "time"
+
+// InterfacePlanEntry is one interface's planned change within a ChangePlan:
+// its current description, the commands that will delete/reset it, and a
+// rollback snippet - the interface's original config lines, verbatim -
+// that restores it if the change needs to be reverted.
+type InterfacePlanEntry struct {
+	Interface   string   `json:"interface" yaml:"interface"`
+	Description string   `json:"description" yaml:"description"`
+	Commands    []string `json:"commands" yaml:"commands"`
+	Rollback    []string `json:"rollback" yaml:"rollback"`
+}
+
+// ChangePlan is a structured, JSON/YAML-serializable dry-run of an interface
+// deletion run for one device, reviewable before anything is sent to it.
+// EnterConfig/ExitConfig bracket the whole run; each InterfacePlanEntry's
+// Commands run in between.
+type ChangePlan struct {
+	Hostname    string               `json:"hostname" yaml:"hostname"`
+	Dialect     string               `json:"dialect" yaml:"dialect"`
+	GeneratedAt time.Time            `json:"generated_at" yaml:"generated_at"`
+	EnterConfig []string             `json:"enter_config" yaml:"enter_config"`
+	ExitConfig  []string             `json:"exit_config" yaml:"exit_config"`
+	Interfaces  []InterfacePlanEntry `json:"interfaces" yaml:"interfaces"`
+}
+
+// GenerateChangePlan builds a ChangePlan for deleting every interface in
+// interfaces from hostname, in dialect's syntax. Each entry's Rollback is
+// the interface's own original Config lines, so reapplying them restores it.
+func GenerateChangePlan(hostname string, dialect ConfigDialect, interfaces []InterfaceInfo) *ChangePlan {
+	plan := &ChangePlan{
+		Hostname:    hostname,
+		Dialect:     dialect.Name(),
+		GeneratedAt: time.Now(),
+		EnterConfig: dialect.EnterConfig(),
+		ExitConfig:  dialect.ExitConfig(),
+	}
+
+	for _, iface := range interfaces {
+		plan.Interfaces = append(plan.Interfaces, InterfacePlanEntry{
+			Interface:   iface.Name,
+			Description: iface.Description,
+			Commands:    dialect.DeleteCommands(iface.Name),
+			Rollback:    append([]string{}, iface.Config...),
+		})
+	}
+
+	return plan
+}
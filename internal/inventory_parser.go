@@ -0,0 +1,368 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Component is a single hardware item extracted from a device's raw
+// inventory command output by an InventoryParser - a chassis, route
+// processor, supervisor, line card, and so on - tagged with a coarse Role so
+// buildReportRows can slot it into the right report column without caring
+// which vendor format produced it.
+type Component struct {
+	Role   string // "chassis", "rp", "esp", or "other"
+	Serial string
+}
+
+// InventoryParser extracts hardware Components from a device's raw
+// inventory command output. Like ConfigDialect, a registry of parsers is
+// tried in registration order via DetectInventoryParser, so report can
+// handle a mixed-vendor fleet without the caller needing to know which
+// command/format a given device used to produce its inventory file.
+type InventoryParser interface {
+	// Name identifies the parser, e.g. "ios", "nxos", "junos", "eos".
+	Name() string
+
+	// Matches reports whether this parser recognizes filename or sample
+	// (the first handful of lines of the inventory output, blank ones
+	// included) as its own format.
+	Matches(filename string, sample []string) bool
+
+	// Parse extracts every hardware component from raw inventory output.
+	Parse(raw []byte) ([]Component, error)
+}
+
+var (
+	inventoryParserRegistry = map[string]InventoryParser{}
+	inventoryParserOrder    []string
+)
+
+// RegisterInventoryParser adds an InventoryParser to the registry under name
+// (used for lookup and, in registration order, for auto-detection priority).
+// Parsers registered later take priority over earlier ones with the same
+// name.
+func RegisterInventoryParser(name string, p InventoryParser) {
+	key := strings.ToLower(name)
+	if _, exists := inventoryParserRegistry[key]; !exists {
+		inventoryParserOrder = append(inventoryParserOrder, key)
+	}
+	inventoryParserRegistry[key] = p
+}
+
+// InventoryParserByName looks up a registered InventoryParser by name.
+func InventoryParserByName(name string) (InventoryParser, bool) {
+	p, ok := inventoryParserRegistry[strings.ToLower(name)]
+	return p, ok
+}
+
+// DetectInventoryParser picks the InventoryParser whose Matches reports true
+// first, in registration order, falling back to "ios" (the Cisco IOS-XE
+// "show inventory" format this package originally hardcoded) if nothing else
+// claims it.
+func DetectInventoryParser(filename string, sample []string) InventoryParser {
+	for _, name := range inventoryParserOrder {
+		if name == "ios" {
+			continue // ios is the fallback, tried last
+		}
+		if p := inventoryParserRegistry[name]; p.Matches(filename, sample) {
+			return p
+		}
+	}
+	return inventoryParserRegistry["ios"]
+}
+
+func init() {
+	RegisterInventoryParser("nxos", nxosInventoryParser{})
+	RegisterInventoryParser("junos", junosInventoryParser{})
+	RegisterInventoryParser("eos", eosInventoryParser{})
+	RegisterInventoryParser("ios", iosInventoryParser{})
+}
+
+// ParseInventoryFile reads filename, detects which vendor format it holds
+// from the filename and a sample of its contents (see DetectInventoryParser),
+// and returns its components split into the buckets buildReportRows expects.
+// A read or parse error yields four nil slices, matching this function's
+// original behavior when the file couldn't be read.
+func ParseInventoryFile(filename string) (chassis, rp, esp, allSerials []string) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, nil, nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	sample := lines
+	if len(sample) > 20 {
+		sample = sample[:20]
+	}
+
+	components, err := DetectInventoryParser(filename, sample).Parse(data)
+	if err != nil {
+		return nil, nil, nil, nil
+	}
+
+	for _, c := range components {
+		switch c.Role {
+		case "chassis":
+			chassis = append(chassis, c.Serial)
+		case "rp":
+			rp = append(rp, c.Serial)
+		case "esp":
+			esp = append(esp, c.Serial)
+		}
+		allSerials = append(allSerials, c.Serial)
+	}
+
+	return chassis, rp, esp, allSerials
+}
+
+// iosInventoryParser covers Cisco IOS-XE "show inventory" output: a NAME
+// line ("Chassis Type: ASR9006", "Route Processor 0", "Embedded Services
+// Processor 0", ...) followed on the next line by "SN: <serial>". This was
+// this package's original hardcoded format, so it's also the fallback when
+// no other parser claims a file.
+type iosInventoryParser struct{}
+
+func (iosInventoryParser) Name() string { return "ios" }
+
+func (iosInventoryParser) Matches(filename string, sample []string) bool {
+	return true // fallback parser, always matches
+}
+
+var iosSerialPattern = regexp.MustCompile(`SN: ([A-Z0-9]+)`)
+
+func (iosInventoryParser) Parse(raw []byte) ([]Component, error) {
+	lines := strings.Split(string(raw), "\n")
+	var components []Component
+
+	for i, line := range lines {
+		switch {
+		case strings.Contains(line, "Chassis") && strings.Contains(line, "ASR"):
+			if i+1 < len(lines) {
+				if matches := iosSerialPattern.FindStringSubmatch(lines[i+1]); len(matches) > 1 {
+					components = append(components, Component{Role: "chassis", Serial: matches[1]})
+				}
+			}
+		case strings.Contains(line, "Route Processor"):
+			if i+1 < len(lines) {
+				if matches := iosSerialPattern.FindStringSubmatch(lines[i+1]); len(matches) > 1 {
+					components = append(components, Component{Role: "rp", Serial: matches[1]})
+				}
+			}
+		case strings.Contains(line, "Embedded Services Processor"):
+			if i+1 < len(lines) {
+				if matches := iosSerialPattern.FindStringSubmatch(lines[i+1]); len(matches) > 1 {
+					components = append(components, Component{Role: "esp", Serial: matches[1]})
+				}
+			}
+		case strings.Contains(line, "SN:"):
+			if matches := iosSerialPattern.FindStringSubmatch(line); len(matches) > 1 {
+				components = append(components, Component{Role: "other", Serial: matches[1]})
+			}
+		}
+	}
+
+	return components, nil
+}
+
+// nxosInventoryParser covers Cisco NX-OS "show inventory" output, a series
+// of quoted-field blocks:
+//
+//	NAME: "Chassis", DESCR: "Nexus9000 C93180YC-EX Chassis"
+//	PID: N9K-C93180YC-EX   , VID: V02 , SN: FOC12345ABC
+//
+// Unlike IOS-XE, the NAME and SN lines aren't a fixed one-apart pair - a
+// DESCR-only continuation is possible - so each NAME line's role is carried
+// forward to whichever of the next few lines actually has an SN field.
+type nxosInventoryParser struct{}
+
+func (nxosInventoryParser) Name() string { return "nxos" }
+
+func (nxosInventoryParser) Matches(filename string, sample []string) bool {
+	lower := strings.ToLower(filename)
+	if strings.Contains(lower, "nxos") || strings.Contains(lower, "nx-os") {
+		return true
+	}
+	for _, line := range sample {
+		if strings.Contains(line, "NAME:") && strings.Contains(line, "DESCR:") {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	nxosNamePattern   = regexp.MustCompile(`NAME:\s*"([^"]*)"`)
+	nxosSerialPattern = regexp.MustCompile(`SN:\s*(\S+)`)
+)
+
+func nxosRole(name string) string {
+	switch {
+	case strings.Contains(name, "Chassis"):
+		return "chassis"
+	case strings.Contains(name, "Supervisor"):
+		return "rp"
+	default:
+		return "other"
+	}
+}
+
+func (nxosInventoryParser) Parse(raw []byte) ([]Component, error) {
+	lines := strings.Split(string(raw), "\n")
+	var components []Component
+	role := "other"
+	haveName := false
+
+	for _, line := range lines {
+		if matches := nxosNamePattern.FindStringSubmatch(line); matches != nil {
+			role = nxosRole(matches[1])
+			haveName = true
+		}
+		if matches := nxosSerialPattern.FindStringSubmatch(line); matches != nil && haveName {
+			components = append(components, Component{Role: role, Serial: matches[1]})
+			haveName = false
+		}
+	}
+
+	return components, nil
+}
+
+// junosInventoryParser covers Juniper Junos "show chassis hardware" output,
+// a fixed-header table whose columns (Item, Version, Part number, Serial
+// number, Description) are separated by runs of two or more spaces:
+//
+//	Item             Version  Part number  Serial number     Description
+//	Chassis                                JN123456789AB     MX960
+//	Routing Engine 0          740-013063   9009012345         RE-S-1800x4
+//
+// Rather than parse fixed column offsets (which shift with content width),
+// each row is split on whitespace runs and the first field that looks like
+// a serial (alphanumeric, no dash, at least 6 characters - long enough to
+// not collide with a short part-like token) is taken as the serial; this
+// rejects dashed part numbers like "740-013063" but accepts "JN123456789AB"
+// or "9009012345".
+type junosInventoryParser struct{}
+
+func (junosInventoryParser) Name() string { return "junos" }
+
+func (junosInventoryParser) Matches(filename string, sample []string) bool {
+	lower := strings.ToLower(filename)
+	if strings.Contains(lower, "junos") || strings.Contains(lower, "juniper") {
+		return true
+	}
+	for _, line := range sample {
+		if strings.Contains(line, "Part number") && strings.Contains(line, "Serial number") {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	junosColumnSplit = regexp.MustCompile(`\s{2,}`)
+	junosSerialField = regexp.MustCompile(`^[A-Z0-9]{6,}$`)
+)
+
+func junosRole(item string) string {
+	switch {
+	case strings.HasPrefix(item, "Chassis"):
+		return "chassis"
+	case strings.HasPrefix(item, "Routing Engine"):
+		return "rp"
+	default:
+		return "other"
+	}
+}
+
+func (junosInventoryParser) Parse(raw []byte) ([]Component, error) {
+	lines := strings.Split(string(raw), "\n")
+	var components []Component
+
+	for _, line := range lines {
+		fields := junosColumnSplit.Split(strings.TrimRight(line, " \t"), -1)
+		if len(fields) < 2 {
+			continue
+		}
+
+		item := strings.TrimSpace(fields[0])
+		if item == "" || item == "Item" {
+			continue
+		}
+
+		var serial string
+		for _, field := range fields[1:] {
+			if junosSerialField.MatchString(field) && !strings.Contains(field, "-") {
+				serial = field
+				break
+			}
+		}
+		if serial == "" {
+			continue
+		}
+
+		components = append(components, Component{Role: junosRole(item), Serial: serial})
+	}
+
+	return components, nil
+}
+
+// eosInventoryParser covers Arista EOS "show inventory" output, a two-column
+// "Name"/"SN" table under a dashed header rule:
+//
+//	Name                     SN
+//	-----------------------  ------------
+//	Chassis                  SSJ12345678
+//	Supervisor Slot          SSJ23456789
+type eosInventoryParser struct{}
+
+func (eosInventoryParser) Name() string { return "eos" }
+
+func (eosInventoryParser) Matches(filename string, sample []string) bool {
+	lower := strings.ToLower(filename)
+	if strings.Contains(lower, "eos") || strings.Contains(lower, "arista") {
+		return true
+	}
+	for _, line := range sample {
+		if strings.Contains(line, "Arista") {
+			return true
+		}
+	}
+	return false
+}
+
+var eosRowPattern = regexp.MustCompile(`^(\S.*?\S|\S)\s{2,}(\S+)\s*$`)
+
+func eosRole(name string) string {
+	switch {
+	case strings.Contains(name, "Chassis"):
+		return "chassis"
+	case strings.Contains(name, "Supervisor"):
+		return "rp"
+	default:
+		return "other"
+	}
+}
+
+func (eosInventoryParser) Parse(raw []byte) ([]Component, error) {
+	lines := strings.Split(string(raw), "\n")
+	var components []Component
+
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "-") || strings.HasPrefix(strings.TrimSpace(line), "Name") {
+			continue
+		}
+		matches := eosRowPattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		components = append(components, Component{Role: eosRole(matches[1]), Serial: matches[2]})
+	}
+
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no SN rows found in Arista inventory output")
+	}
+
+	return components, nil
+}
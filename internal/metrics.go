@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MetricSample is a single labeled Prometheus time series value.
+type MetricSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// MetricFamily is one named Prometheus metric (gauge or counter) and its
+// samples, rendered as a "# HELP"/"# TYPE" pair followed by one line per
+// sample - the same shape GenerateInventoryReport's prometheus format and
+// BulkInventory's collection-error counter both write, via WriteTextfile.
+type MetricFamily struct {
+	Name    string
+	Help    string
+	Type    string // "gauge" or "counter"
+	Samples []MetricSample
+}
+
+// WriteTextfile renders families in the Prometheus text exposition format
+// node_exporter's textfile collector expects and writes them to path. The
+// file is written to a temporary path in the same directory and renamed into
+// place, since node_exporter polls the textfile directory on its own
+// schedule and would otherwise have a chance of reading a partially-written
+// file.
+func WriteTextfile(path string, families []MetricFamily) error {
+	var b strings.Builder
+	for _, f := range families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.Name, f.Help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.Name, f.Type)
+		for _, s := range f.Samples {
+			b.WriteString(f.Name)
+			b.WriteString(formatLabels(s.Labels))
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatFloat(s.Value, 'g', -1, 64))
+			b.WriteByte('\n')
+		}
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("error creating temp textfile: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing temp textfile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error closing temp textfile: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error renaming textfile into place: %w", err)
+	}
+	return nil
+}
+
+// formatLabels renders a sample's labels as "{k="v",...}", sorted by key for
+// deterministic output, or "" when there are none.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%q", k, labels[k])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -1,75 +1,339 @@
 package internal
 
 import (
-	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"tomclient/output"
 )
 
-// GenerateInventoryReport generates a CSV report from inventory files
-func GenerateInventoryReport(inventoryDir string) error {
-	files, err := filepath.Glob(filepath.Join(inventoryDir, "*_inventory.txt"))
-	if err != nil {
-		return fmt.Errorf("error finding inventory files: %w", err)
+// ReportFormat selects the file format GenerateInventoryReport writes.
+type ReportFormat string
+
+const (
+	ReportFormatCSV        ReportFormat = "csv"
+	ReportFormatJSON       ReportFormat = "json"
+	ReportFormatXLSX       ReportFormat = "xlsx"
+	ReportFormatMarkdown   ReportFormat = "markdown"
+	ReportFormatPrometheus ReportFormat = "prometheus"
+)
+
+var reportHeaders = []string{
+	"Hostname", "Chassis_SN", "Chassis_Age", "Chassis_Year",
+	"RP1_SN", "RP1_Age", "RP2_SN", "RP2_Age",
+	"ESP1_SN", "ESP1_Age", "ESP2_SN", "ESP2_Age",
+	"Avg_Major_Age", "Avg_All_Age", "Source_File",
+}
+
+// DeviceReportRow is a single device's row in the inventory report.
+type DeviceReportRow struct {
+	Hostname    string  `json:"hostname"`
+	ChassisSN   string  `json:"chassis_sn,omitempty"`
+	ChassisAge  string  `json:"chassis_age,omitempty"`
+	ChassisYear string  `json:"chassis_manufacture_year,omitempty"`
+	RP1SN       string  `json:"rp1_sn,omitempty"`
+	RP1Age      string  `json:"rp1_age,omitempty"`
+	RP2SN       string  `json:"rp2_sn,omitempty"`
+	RP2Age      string  `json:"rp2_age,omitempty"`
+	ESP1SN      string  `json:"esp1_sn,omitempty"`
+	ESP1Age     string  `json:"esp1_age,omitempty"`
+	ESP2SN      string  `json:"esp2_sn,omitempty"`
+	ESP2Age     string  `json:"esp2_age,omitempty"`
+	AvgMajorAge float64 `json:"avg_major_age"`
+	AvgAllAge   float64 `json:"avg_all_age"`
+	SourceFile  string  `json:"source_file"`
+}
+
+// csvRow renders the row in the same column order as reportHeaders.
+func (r DeviceReportRow) csvRow() []string {
+	return []string{
+		r.Hostname, r.ChassisSN, r.ChassisAge, r.ChassisYear,
+		r.RP1SN, r.RP1Age, r.RP2SN, r.RP2Age,
+		r.ESP1SN, r.ESP1Age, r.ESP2SN, r.ESP2Age,
+		fmt.Sprintf("%.1f", r.AvgMajorAge), fmt.Sprintf("%.1f", r.AvgAllAge),
+		r.SourceFile,
+	}
+}
+
+// ageString formats a serial number's decoded age, or "unknown" when no
+// registered AgeDecoder recognizes its format.
+func ageString(serial string) string {
+	age, ok := CalculateAge(serial)
+	if !ok {
+		return "unknown"
 	}
-	
-	csvFile, err := os.Create("inventory_report.csv")
+	return strconv.Itoa(age)
+}
+
+// yearString formats a serial number's decoded manufacture year, or "" when
+// no registered AgeDecoder recognizes its format.
+func yearString(serial string) string {
+	year, ok := DecodeAge(serial)
+	if !ok {
+		return ""
+	}
+	return strconv.Itoa(year)
+}
+
+// buildReportRows parses every *_inventory.txt file in inventoryDir into a
+// DeviceReportRow, sorted by source filename for deterministic output.
+func buildReportRows(inventoryDir string) ([]DeviceReportRow, error) {
+	files, err := filepath.Glob(filepath.Join(inventoryDir, "*_inventory.txt"))
 	if err != nil {
-		return fmt.Errorf("error creating CSV file: %w", err)
+		return nil, fmt.Errorf("error finding inventory files: %w", err)
 	}
-	defer csvFile.Close()
-	
-	writer := csv.NewWriter(csvFile)
-	defer writer.Flush()
-	
-	headers := []string{"Hostname", "Chassis_SN", "Chassis_Age", "RP1_SN", "RP1_Age", "RP2_SN", "RP2_Age", "ESP1_SN", "ESP1_Age", "ESP2_SN", "ESP2_Age", "Avg_Major_Age", "Avg_All_Age"}
-	writer.Write(headers)
-	
+	sort.Strings(files)
+
+	rows := make([]DeviceReportRow, 0, len(files))
 	for _, file := range files {
 		basename := filepath.Base(file)
 		hostname := strings.TrimSuffix(basename, "_inventory.txt")
-		
+
 		chassis, rp, esp, allSerials := ParseInventoryFile(file)
-		
-		row := []string{hostname}
-		
+
+		row := DeviceReportRow{Hostname: hostname, SourceFile: basename}
+
 		if len(chassis) > 0 {
-			row = append(row, chassis[0], strconv.Itoa(CalculateAge(chassis[0])))
-		} else {
-			row = append(row, "", "")
+			row.ChassisSN = chassis[0]
+			row.ChassisAge = ageString(chassis[0])
+			row.ChassisYear = yearString(chassis[0])
 		}
-		
-		for i := 0; i < 2; i++ {
-			if i < len(rp) {
-				row = append(row, rp[i], strconv.Itoa(CalculateAge(rp[i])))
-			} else {
-				row = append(row, "", "")
-			}
+		if len(rp) > 0 {
+			row.RP1SN = rp[0]
+			row.RP1Age = ageString(rp[0])
 		}
-		
-		for i := 0; i < 2; i++ {
-			if i < len(esp) {
-				row = append(row, esp[i], strconv.Itoa(CalculateAge(esp[i])))
-			} else {
-				row = append(row, "", "")
-			}
+		if len(rp) > 1 {
+			row.RP2SN = rp[1]
+			row.RP2Age = ageString(rp[1])
+		}
+		if len(esp) > 0 {
+			row.ESP1SN = esp[0]
+			row.ESP1Age = ageString(esp[0])
+		}
+		if len(esp) > 1 {
+			row.ESP2SN = esp[1]
+			row.ESP2Age = ageString(esp[1])
 		}
-		
-		majorSerials := make([]string, 0)
+
+		majorSerials := make([]string, 0, len(chassis)+len(rp)+len(esp))
 		majorSerials = append(majorSerials, chassis...)
 		majorSerials = append(majorSerials, rp...)
 		majorSerials = append(majorSerials, esp...)
-		
-		avgMajorAge := CalculateAverageAge(majorSerials)
-		avgAllAge := CalculateAverageAge(allSerials)
-		
-		row = append(row, fmt.Sprintf("%.1f", avgMajorAge), fmt.Sprintf("%.1f", avgAllAge))
-		
-		writer.Write(row)
-	}
-	
+
+		row.AvgMajorAge = CalculateAverageAge(majorSerials)
+		row.AvgAllAge = CalculateAverageAge(allSerials)
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// detectReportFormat infers a report format from outputPath's extension,
+// defaulting to CSV for anything it doesn't recognize.
+func detectReportFormat(outputPath string) ReportFormat {
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".json":
+		return ReportFormatJSON
+	case ".xlsx":
+		return ReportFormatXLSX
+	case ".md":
+		return ReportFormatMarkdown
+	case ".prom":
+		return ReportFormatPrometheus
+	default:
+		return ReportFormatCSV
+	}
+}
+
+// GenerateInventoryReport parses inventory files in inventoryDir and writes
+// a report to outputPath in the given format. An empty format is inferred
+// from outputPath's extension.
+func GenerateInventoryReport(inventoryDir, outputPath string, format ReportFormat) error {
+	rows, err := buildReportRows(inventoryDir)
+	if err != nil {
+		return err
+	}
+
+	if format == "" {
+		format = detectReportFormat(outputPath)
+	}
+
+	switch format {
+	case ReportFormatCSV:
+		return writeReportCSV(rows, outputPath)
+	case ReportFormatJSON:
+		return writeReportJSON(rows, outputPath)
+	case ReportFormatXLSX:
+		return writeReportXLSX(rows, outputPath)
+	case ReportFormatMarkdown:
+		return writeReportMarkdown(rows, outputPath)
+	case ReportFormatPrometheus:
+		return writeReportPrometheus(rows, outputPath)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// reportCSVColumns projects a DeviceReportRow onto reportHeaders via its
+// csvRow(), so the CSV report shares its rendering with the shared output
+// package instead of hand-rolling a csv.Writer.
+var reportCSVColumns = func() []output.Column {
+	columns := make([]output.Column, len(reportHeaders))
+	for i, header := range reportHeaders {
+		i := i
+		columns[i] = output.Column{
+			Header: header,
+			Value:  func(row interface{}) string { return row.(DeviceReportRow).csvRow()[i] },
+		}
+	}
+	return columns
+}()
+
+func writeReportCSV(rows []DeviceReportRow, outputPath string) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("error creating CSV file: %w", err)
+	}
+	defer file.Close()
+
+	anyRows := make([]interface{}, len(rows))
+	for i, row := range rows {
+		anyRows[i] = row
+	}
+
+	return output.Render(file, output.FormatCSV, rows, reportCSVColumns, anyRows)
+}
+
+func writeReportJSON(rows []DeviceReportRow, outputPath string) error {
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling report: %w", err)
+	}
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing JSON report: %w", err)
+	}
+	return nil
+}
+
+func writeReportMarkdown(rows []DeviceReportRow, outputPath string) error {
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(reportHeaders, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(reportHeaders)) + "\n")
+	for _, row := range rows {
+		b.WriteString("| " + strings.Join(row.csvRow(), " | ") + " |\n")
+	}
+
+	if err := os.WriteFile(outputPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("error writing markdown report: %w", err)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+func writeReportXLSX(rows []DeviceReportRow, outputPath string) error {
+	f := excelize.NewFile()
+	sheet := "Report"
+	f.SetSheetName(f.GetSheetName(0), sheet)
+
+	for i, header := range reportHeaders {
+		cell, err := excelize.CoordinatesToCellName(i+1, 1)
+		if err != nil {
+			return err
+		}
+		f.SetCellValue(sheet, cell, header)
+	}
+
+	for r, row := range rows {
+		for c, value := range row.csvRow() {
+			cell, err := excelize.CoordinatesToCellName(c+1, r+2)
+			if err != nil {
+				return err
+			}
+			f.SetCellValue(sheet, cell, value)
+		}
+	}
+
+	if err := f.SaveAs(outputPath); err != nil {
+		return fmt.Errorf("error writing xlsx report: %w", err)
+	}
+	return nil
+}
+
+// writeReportPrometheus renders rows as a node_exporter textfile collector
+// file: one tom_device_component_age_years gauge per component with a
+// recognized serial format, a tom_device_component_count gauge per device,
+// and a single tom_collection_timestamp_seconds gauge marking when the
+// report was generated.
+func writeReportPrometheus(rows []DeviceReportRow, outputPath string) error {
+	var ageSamples []MetricSample
+	var countSamples []MetricSample
+
+	for _, row := range rows {
+		count := 0
+		for _, c := range []struct {
+			role, componentType, serial, age string
+		}{
+			{"chassis", "chassis", row.ChassisSN, row.ChassisAge},
+			{"rp1", "rp", row.RP1SN, row.RP1Age},
+			{"rp2", "rp", row.RP2SN, row.RP2Age},
+			{"esp1", "esp", row.ESP1SN, row.ESP1Age},
+			{"esp2", "esp", row.ESP2SN, row.ESP2Age},
+		} {
+			if c.serial == "" {
+				continue
+			}
+			count++
+
+			age, err := strconv.Atoi(c.age)
+			if err != nil {
+				continue // "unknown" - no registered AgeDecoder recognized this serial
+			}
+			ageSamples = append(ageSamples, MetricSample{
+				Labels: map[string]string{
+					"hostname":       row.Hostname,
+					"role":           c.role,
+					"serial":         c.serial,
+					"component_type": c.componentType,
+				},
+				Value: float64(age),
+			})
+		}
+
+		countSamples = append(countSamples, MetricSample{
+			Labels: map[string]string{"hostname": row.Hostname},
+			Value:  float64(count),
+		})
+	}
+
+	families := []MetricFamily{
+		{
+			Name:    "tom_device_component_age_years",
+			Help:    "Age in years of a hardware component, decoded from its serial number",
+			Type:    "gauge",
+			Samples: ageSamples,
+		},
+		{
+			Name:    "tom_device_component_count",
+			Help:    "Number of hardware components found in a device's inventory",
+			Type:    "gauge",
+			Samples: countSamples,
+		},
+		{
+			Name: "tom_collection_timestamp_seconds",
+			Help: "Unix timestamp of when this inventory report was generated",
+			Type: "gauge",
+			Samples: []MetricSample{
+				{Value: float64(time.Now().Unix())},
+			},
+		},
+	}
+
+	return WriteTextfile(outputPath, families)
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGenerateInventoryReportCSV compares generated CSV output against a
+// golden file. Age columns depend on time.Now().Year(), so the golden file
+// holds {{PLACEHOLDER}} tokens that are substituted with the expected ages
+// (computed from the fixture's known manufacture years) before comparison.
+func TestGenerateInventoryReportCSV(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.csv")
+
+	if err := GenerateInventoryReport("testdata", outputPath, ReportFormatCSV); err != nil {
+		t.Fatalf("GenerateInventoryReport returned error: %v", err)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read generated report: %v", err)
+	}
+
+	want, err := os.ReadFile(filepath.Join("testdata", "report.csv.golden"))
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	year := time.Now().Year()
+	chassisAge := year - 2020
+	rp1Age := year - 2018
+	esp1Age := year - 2022
+	avgMajorAge := float64(chassisAge+rp1Age+esp1Age) / 3
+
+	replacer := strings.NewReplacer(
+		"{{CHASSIS_AGE}}", strconv.Itoa(chassisAge),
+		"{{RP1_AGE}}", strconv.Itoa(rp1Age),
+		"{{ESP1_AGE}}", strconv.Itoa(esp1Age),
+		"{{AVG_MAJOR_AGE}}", fmt.Sprintf("%.1f", avgMajorAge),
+		"{{AVG_ALL_AGE}}", fmt.Sprintf("%.1f", avgMajorAge),
+	)
+	wantResolved := replacer.Replace(string(want))
+
+	if string(got) != wantResolved {
+		t.Errorf("report mismatch:\ngot:\n%s\nwant:\n%s", got, wantResolved)
+	}
+}
+
+func TestDetectReportFormat(t *testing.T) {
+	cases := map[string]ReportFormat{
+		"report.csv":  ReportFormatCSV,
+		"report.json": ReportFormatJSON,
+		"report.xlsx": ReportFormatXLSX,
+		"report.md":   ReportFormatMarkdown,
+		"report.prom": ReportFormatPrometheus,
+		"report":      ReportFormatCSV,
+		"REPORT.JSON": ReportFormatJSON,
+	}
+
+	for path, want := range cases {
+		if got := detectReportFormat(path); got != want {
+			t.Errorf("detectReportFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryOptions configures RunConcurrent's per-item retry loop: an item is
+// retried with exponential backoff (plus jitter) until it succeeds, ctx is
+// cancelled, or RetryTimeout/MaxAttempts is reached, whichever comes first.
+type RetryOptions struct {
+	MaxAttempts  int
+	Interval     time.Duration
+	MaxInterval  time.Duration
+	RetryTimeout time.Duration
+	Jitter       time.Duration
+	RetryOn      func(err error) bool
+}
+
+const (
+	defaultRunnerMaxAttempts  = 5
+	defaultRunnerInterval     = 2 * time.Second
+	defaultRunnerMaxInterval  = 30 * time.Second
+	defaultRunnerRetryTimeout = 2 * time.Minute
+)
+
+func (o *RetryOptions) withDefaults() {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = defaultRunnerMaxAttempts
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultRunnerInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaultRunnerMaxInterval
+	}
+	if o.RetryTimeout <= 0 {
+		o.RetryTimeout = defaultRunnerRetryTimeout
+	}
+	if o.RetryOn == nil {
+		o.RetryOn = func(error) bool { return true }
+	}
+}
+
+// ItemResult reports how one RunConcurrent item finished, so a caller can
+// tell "succeeded first try" apart from "succeeded after retries" and
+// "gave up"/"cancelled", and print a clear per-host summary.
+type ItemResult struct {
+	Item     string
+	Attempts int
+	Err      error
+}
+
+// RunConcurrent runs work for every item under a concurrency-bounded worker
+// pool, the same sem-plus-WaitGroup shape BulkInventory and
+// collectDeviceInterfaces used to each implement on their own. On failure,
+// work is retried in place with exponential backoff and jitter per retry,
+// until it succeeds, ctx is cancelled, or retry's MaxAttempts/RetryTimeout is
+// reached. Results are returned in the same order as items.
+func RunConcurrent(ctx context.Context, items []string, concurrency int, retry RetryOptions, work func(ctx context.Context, item string) error) []ItemResult {
+	retry.withDefaults()
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	results := make([]ItemResult, len(items))
+
+	for i, item := range items {
+		wg.Add(1)
+		go func(i int, item string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = runItemWithRetry(ctx, item, retry, work)
+		}(i, item)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func runItemWithRetry(ctx context.Context, item string, retry RetryOptions, work func(ctx context.Context, item string) error) ItemResult {
+	deadline := time.Now().Add(retry.RetryTimeout)
+	interval := retry.Interval
+	attempt := 0
+
+	for {
+		attempt++
+
+		err := work(ctx, item)
+		if err == nil {
+			return ItemResult{Item: item, Attempts: attempt}
+		}
+
+		if !retry.RetryOn(err) || attempt >= retry.MaxAttempts || time.Now().After(deadline) {
+			return ItemResult{Item: item, Attempts: attempt, Err: err}
+		}
+
+		sleep := interval
+		if retry.Jitter > 0 {
+			sleep += time.Duration(rand.Int63n(int64(retry.Jitter)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ItemResult{Item: item, Attempts: attempt, Err: ctx.Err()}
+		case <-time.After(sleep):
+		}
+
+		interval *= 2
+		if interval > retry.MaxInterval {
+			interval = retry.MaxInterval
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package output provides a shared, multi-format renderer (JSON, YAML,
+// table, CSV) for commands that would otherwise each hand-roll their own
+// format switch. Callers describe their data once as a slice of Columns and
+// rows, and the package owns picking an encoding, streaming it to a writer,
+// and printing a CSV/table header - so adding a new output format to one
+// command doesn't mean copy-pasting a switch statement into every other one.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is one of the output encodings Render and StreamWriter support.
+type Format string
+
+const (
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+	FormatTable Format = "table"
+	FormatCSV   Format = "csv"
+)
+
+// ParseFormat validates a --output flag value, defaulting an empty string to
+// FormatTable.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "":
+		return FormatTable, nil
+	case FormatJSON, FormatYAML, FormatTable, FormatCSV:
+		return Format(s), nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q (must be one of: json, yaml, table, csv)", s)
+	}
+}
+
+// Column is one rendered field of a table/csv row. Value extracts the
+// column's text from a single row of the caller's choosing - typically a
+// struct value passed through as interface{}.
+type Column struct {
+	Header string
+	Value  func(row interface{}) string
+}
+
+// Render writes data in the requested format to w. For FormatJSON and
+// FormatYAML it marshals data directly, so callers get the full structure
+// with no lossy column projection; for FormatTable and FormatCSV it renders
+// rows through columns instead, since both of those are inherently
+// columnar.
+func Render(w io.Writer, format Format, data interface{}, columns []Column, rows []interface{}) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+
+	case FormatYAML:
+		out, err := yaml.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal yaml: %w", err)
+		}
+		_, err = w.Write(out)
+		return err
+
+	case FormatCSV:
+		sw := NewStreamWriter(w, FormatCSV, columns)
+		for _, row := range rows {
+			if err := sw.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return sw.Close()
+
+	case FormatTable:
+		sw := NewStreamWriter(w, FormatTable, columns)
+		for _, row := range rows {
+			if err := sw.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return sw.Close()
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// StreamWriter renders table/csv rows one at a time. CSV rows are flushed
+// after each one, so a large CSV export never has to buffer every row in
+// memory before writing the first one; table rows are held by the
+// tabwriter until Close, which needs them all to compute column widths. JSON and YAML don't stream the same way (they need
+// the whole value up front to produce valid output), so they're handled by
+// Render directly rather than through a StreamWriter.
+type StreamWriter struct {
+	format  Format
+	columns []Column
+	csv     *csv.Writer
+	table   *tabwriter.Writer
+	wrote   bool
+}
+
+// NewStreamWriter returns a StreamWriter for format over the given columns.
+// format must be FormatCSV or FormatTable.
+func NewStreamWriter(w io.Writer, format Format, columns []Column) *StreamWriter {
+	sw := &StreamWriter{format: format, columns: columns}
+	switch format {
+	case FormatCSV:
+		sw.csv = csv.NewWriter(w)
+	default:
+		sw.table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	}
+	return sw
+}
+
+// WriteRow renders a single row, writing the header first if this is the
+// first row written.
+func (sw *StreamWriter) WriteRow(row interface{}) error {
+	if !sw.wrote {
+		if err := sw.writeHeader(); err != nil {
+			return err
+		}
+		sw.wrote = true
+	}
+
+	record := make([]string, len(sw.columns))
+	for i, col := range sw.columns {
+		record[i] = col.Value(row)
+	}
+
+	if sw.csv != nil {
+		if err := sw.csv.Write(record); err != nil {
+			return err
+		}
+		sw.csv.Flush()
+		return sw.csv.Error()
+	}
+
+	fmt.Fprintln(sw.table, strings.Join(record, "\t"))
+	return nil
+}
+
+func (sw *StreamWriter) writeHeader() error {
+	header := make([]string, len(sw.columns))
+	for i, col := range sw.columns {
+		header[i] = col.Header
+	}
+
+	if sw.csv != nil {
+		if err := sw.csv.Write(header); err != nil {
+			return err
+		}
+		sw.csv.Flush()
+		return sw.csv.Error()
+	}
+
+	fmt.Fprintln(sw.table, strings.Join(header, "\t"))
+	return nil
+}
+
+// Close flushes any buffered output. For FormatTable this is where column
+// widths are actually computed and written out, so it must be called even
+// if no rows were written, to emit the header.
+func (sw *StreamWriter) Close() error {
+	if !sw.wrote {
+		if err := sw.writeHeader(); err != nil {
+			return err
+		}
+	}
+	if sw.table != nil {
+		return sw.table.Flush()
+	}
+	return sw.csv.Error()
+}
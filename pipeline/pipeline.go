@@ -0,0 +1,293 @@
+// Package pipeline reinjects collected artifacts (inventory/*_inventory.txt,
+// interfaces/*_interfaces.txt) through a user-configured parse -> enrich ->
+// assert -> export graph, so a new processing step - flagging EoL hardware,
+// tagging a device with its site from a static lookup, re-running just the
+// export against an already-parsed fleet - doesn't mean editing
+// internal.GenerateInventoryReport's CSV writer. Each stage kind is its own
+// registry of named factories, mirroring internal.ConfigDialect/AgeDecoder/
+// InventoryParser, so a caller builds a graph from a YAML config instead of
+// wiring Go types together by hand.
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Record is one device's data as it flows through the pipeline: components
+// parsed from its inventory, its interface count from its collected config,
+// and whatever Tags enrich stages have attached along the way (a DNS name, a
+// CMDB site code, and so on).
+type Record struct {
+	Hostname       string
+	Components     []Component
+	InterfaceCount int
+	Tags           map[string]string
+}
+
+// Component mirrors internal.Component (role + serial) without importing
+// internal from this package, since stages.go's built-in parsers populate it
+// from internal.ParseInventoryFile's results.
+type Component struct {
+	Role   string
+	Serial string
+}
+
+// Tag sets key on the record's Tags map, creating it if necessary.
+func (r *Record) Tag(key, value string) {
+	if r.Tags == nil {
+		r.Tags = make(map[string]string)
+	}
+	r.Tags[key] = value
+}
+
+// AssertionResult is one assertion stage's verdict on one record.
+type AssertionResult struct {
+	Hostname string
+	Stage    string
+	Passed   bool
+	Message  string
+}
+
+// Parser turns collected artifacts under artifactsDir into Records, one per
+// device. Multiple parse stages merge into the same Record by Hostname, so
+// an "inventory" parser and an "interfaces" parser can each contribute their
+// own fields to the same device.
+type Parser interface {
+	Name() string
+	Parse(artifactsDir string) ([]Record, error)
+}
+
+// Enricher adds or overwrites fields on a single record - a reverse DNS
+// lookup, a static tag pulled from the stage's config, a CMDB call in a
+// fuller deployment.
+type Enricher interface {
+	Name() string
+	Enrich(rec *Record) error
+}
+
+// Assertion checks a single record against some condition ("chassis age <
+// 7") and reports whether it passed.
+type Assertion interface {
+	Name() string
+	Check(rec Record) AssertionResult
+}
+
+// Exporter writes the final set of records and assertion results somewhere -
+// a CSV file, a JSON file, a Prometheus textfile.
+type Exporter interface {
+	Name() string
+	Export(records []Record, results []AssertionResult) error
+}
+
+// ParserFactory, EnricherFactory, AssertionFactory, and ExporterFactory build
+// a stage instance from the string params a StageConfig's YAML supplies, so
+// a stage like "max-age" can be parameterized (max_years: "7") without the
+// registry needing to know its shape in advance.
+type (
+	ParserFactory    func(params map[string]string) (Parser, error)
+	EnricherFactory  func(params map[string]string) (Enricher, error)
+	AssertionFactory func(params map[string]string) (Assertion, error)
+	ExporterFactory  func(params map[string]string) (Exporter, error)
+)
+
+var (
+	parserRegistry    = map[string]ParserFactory{}
+	enricherRegistry  = map[string]EnricherFactory{}
+	assertionRegistry = map[string]AssertionFactory{}
+	exporterRegistry  = map[string]ExporterFactory{}
+)
+
+// RegisterParser, RegisterEnricher, RegisterAssertion, and RegisterExporter
+// add a named stage factory to the corresponding registry, overwriting any
+// earlier factory registered under the same name.
+func RegisterParser(name string, f ParserFactory) {
+	parserRegistry[strings.ToLower(name)] = f
+}
+
+func RegisterEnricher(name string, f EnricherFactory) {
+	enricherRegistry[strings.ToLower(name)] = f
+}
+
+func RegisterAssertion(name string, f AssertionFactory) {
+	assertionRegistry[strings.ToLower(name)] = f
+}
+
+func RegisterExporter(name string, f ExporterFactory) {
+	exporterRegistry[strings.ToLower(name)] = f
+}
+
+// StageConfig names one stage and the params its factory needs - e.g. {Name:
+// "max-age", Params: {"max_years": "7"}}.
+type StageConfig struct {
+	Name   string            `yaml:"name"`
+	Params map[string]string `yaml:"params,omitempty"`
+}
+
+// Config is a pipeline definition loaded from YAML: which parse, enrich,
+// assert, and export stages to run, in the order given.
+type Config struct {
+	Parse  []StageConfig `yaml:"parse"`
+	Enrich []StageConfig `yaml:"enrich"`
+	Assert []StageConfig `yaml:"assert"`
+	Export []StageConfig `yaml:"export"`
+}
+
+// LoadConfig reads and parses a pipeline YAML file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading pipeline config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing pipeline config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Result is what Run produces: every record the parse stages built (after
+// enrichment), and every assertion's verdict on each of them.
+type Result struct {
+	Records    []Record
+	Assertions []AssertionResult
+}
+
+// Run builds each configured stage from its registry, then parses,
+// enriches, asserts, and exports in that order. Records from every parse
+// stage are merged by Hostname before enrichment runs, so later stages never
+// need to know which parser contributed which field.
+func Run(cfg *Config, artifactsDir string) (*Result, error) {
+	records, err := runParsers(cfg.Parse, artifactsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runEnrichers(cfg.Enrich, records); err != nil {
+		return nil, err
+	}
+
+	results := runAssertions(cfg.Assert, records)
+
+	if err := runExporters(cfg.Export, records, results); err != nil {
+		return nil, err
+	}
+
+	return &Result{Records: records, Assertions: results}, nil
+}
+
+func runParsers(stages []StageConfig, artifactsDir string) ([]Record, error) {
+	byHost := make(map[string]*Record)
+	var order []string
+
+	for _, sc := range stages {
+		factory, ok := parserRegistry[strings.ToLower(sc.Name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown parse stage %q", sc.Name)
+		}
+		parser, err := factory(sc.Params)
+		if err != nil {
+			return nil, fmt.Errorf("parse stage %q: %w", sc.Name, err)
+		}
+
+		parsed, err := parser.Parse(artifactsDir)
+		if err != nil {
+			return nil, fmt.Errorf("parse stage %q: %w", sc.Name, err)
+		}
+
+		for _, rec := range parsed {
+			rec := rec
+			existing, ok := byHost[rec.Hostname]
+			if !ok {
+				order = append(order, rec.Hostname)
+				byHost[rec.Hostname] = &rec
+				continue
+			}
+			mergeRecord(existing, &rec)
+		}
+	}
+
+	merged := make([]Record, len(order))
+	for i, hostname := range order {
+		merged[i] = *byHost[hostname]
+	}
+	return merged, nil
+}
+
+// mergeRecord folds src's fields into dst: src's Components are appended,
+// and a non-zero InterfaceCount and every Tag overwrite dst's, so an "inventory" parser's Components and an "interfaces" parser's
+// InterfaceCount can both land on the same device's Record regardless of
+// which stage ran first.
+func mergeRecord(dst, src *Record) {
+	if len(src.Components) > 0 {
+		dst.Components = append(dst.Components, src.Components...)
+	}
+	if src.InterfaceCount > 0 {
+		dst.InterfaceCount = src.InterfaceCount
+	}
+	for k, v := range src.Tags {
+		dst.Tag(k, v)
+	}
+}
+
+func runEnrichers(stages []StageConfig, records []Record) error {
+	for _, sc := range stages {
+		factory, ok := enricherRegistry[strings.ToLower(sc.Name)]
+		if !ok {
+			return fmt.Errorf("unknown enrich stage %q", sc.Name)
+		}
+		enricher, err := factory(sc.Params)
+		if err != nil {
+			return fmt.Errorf("enrich stage %q: %w", sc.Name, err)
+		}
+
+		for i := range records {
+			if err := enricher.Enrich(&records[i]); err != nil {
+				return fmt.Errorf("enrich stage %q on %s: %w", sc.Name, records[i].Hostname, err)
+			}
+		}
+	}
+	return nil
+}
+
+func runAssertions(stages []StageConfig, records []Record) []AssertionResult {
+	var results []AssertionResult
+	for _, sc := range stages {
+		factory, ok := assertionRegistry[strings.ToLower(sc.Name)]
+		if !ok {
+			results = append(results, AssertionResult{Stage: sc.Name, Passed: false, Message: fmt.Sprintf("unknown assert stage %q", sc.Name)})
+			continue
+		}
+		assertion, err := factory(sc.Params)
+		if err != nil {
+			results = append(results, AssertionResult{Stage: sc.Name, Passed: false, Message: err.Error()})
+			continue
+		}
+
+		for _, rec := range records {
+			results = append(results, assertion.Check(rec))
+		}
+	}
+	return results
+}
+
+func runExporters(stages []StageConfig, records []Record, results []AssertionResult) error {
+	for _, sc := range stages {
+		factory, ok := exporterRegistry[strings.ToLower(sc.Name)]
+		if !ok {
+			return fmt.Errorf("unknown export stage %q", sc.Name)
+		}
+		exporter, err := factory(sc.Params)
+		if err != nil {
+			return fmt.Errorf("export stage %q: %w", sc.Name, err)
+		}
+		if err := exporter.Export(records, results); err != nil {
+			return fmt.Errorf("export stage %q: %w", sc.Name, err)
+		}
+	}
+	return nil
+}
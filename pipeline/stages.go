@@ -0,0 +1,289 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tomclient/internal"
+	"tomclient/output"
+)
+
+func init() {
+	RegisterParser("inventory", newInventoryParserStage)
+	RegisterParser("interfaces", newInterfacesParserStage)
+
+	RegisterEnricher("static-tags", newStaticTagsEnricher)
+	RegisterEnricher("dns", newDNSEnricher)
+
+	RegisterAssertion("max-age", newMaxAgeAssertion)
+
+	RegisterExporter("csv", newCSVExporter)
+	RegisterExporter("json", newJSONExporter)
+}
+
+// inventoryParserStage reads artifactsDir/inventory/*_inventory.txt via
+// internal.ParseInventoryFile (which auto-detects vendor format - see
+// internal.InventoryParser), so the pipeline supports the same mixed fleet
+// internal.GenerateInventoryReport does.
+type inventoryParserStage struct{}
+
+func newInventoryParserStage(params map[string]string) (Parser, error) {
+	return inventoryParserStage{}, nil
+}
+
+func (inventoryParserStage) Name() string { return "inventory" }
+
+func (inventoryParserStage) Parse(artifactsDir string) ([]Record, error) {
+	files, err := filepath.Glob(filepath.Join(artifactsDir, "inventory", "*_inventory.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("error finding inventory files: %w", err)
+	}
+	sort.Strings(files)
+
+	records := make([]Record, 0, len(files))
+	for _, file := range files {
+		hostname := strings.TrimSuffix(filepath.Base(file), "_inventory.txt")
+		chassis, rp, esp, _ := internal.ParseInventoryFile(file)
+
+		rec := Record{Hostname: hostname}
+		for _, sn := range chassis {
+			rec.Components = append(rec.Components, Component{Role: "chassis", Serial: sn})
+		}
+		for _, sn := range rp {
+			rec.Components = append(rec.Components, Component{Role: "rp", Serial: sn})
+		}
+		for _, sn := range esp {
+			rec.Components = append(rec.Components, Component{Role: "esp", Serial: sn})
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// interfacesParserStage reads artifactsDir/interfaces/*_interfaces.txt via
+// internal.ParseInterfaceConfig, contributing each device's interface count
+// to its Record.
+type interfacesParserStage struct{}
+
+func newInterfacesParserStage(params map[string]string) (Parser, error) {
+	return interfacesParserStage{}, nil
+}
+
+func (interfacesParserStage) Name() string { return "interfaces" }
+
+func (interfacesParserStage) Parse(artifactsDir string) ([]Record, error) {
+	files, err := filepath.Glob(filepath.Join(artifactsDir, "interfaces", "*_interfaces.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("error finding interface files: %w", err)
+	}
+	sort.Strings(files)
+
+	records := make([]Record, 0, len(files))
+	for _, file := range files {
+		info, err := internal.ParseInterfaceConfig(file)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, Record{Hostname: info.Hostname, InterfaceCount: len(info.Interfaces)})
+	}
+
+	return records, nil
+}
+
+// staticTagsEnricher attaches every param in its stage config as a tag on
+// every record - the simplest stand-in for a CMDB lookup: "site: iad1" in
+// the YAML becomes rec.Tags["site"] = "iad1" on each device.
+type staticTagsEnricher struct {
+	tags map[string]string
+}
+
+func newStaticTagsEnricher(params map[string]string) (Enricher, error) {
+	return staticTagsEnricher{tags: params}, nil
+}
+
+func (staticTagsEnricher) Name() string { return "static-tags" }
+
+func (e staticTagsEnricher) Enrich(rec *Record) error {
+	for k, v := range e.tags {
+		rec.Tag(k, v)
+	}
+	return nil
+}
+
+// dnsEnricher tags a record with the first IP address its hostname resolves
+// to. A lookup failure doesn't fail the pipeline - it's recorded as a tag
+// instead, since an unresolvable hostname is a fact about the device, not a
+// reason to abort everyone else's enrichment.
+type dnsEnricher struct{}
+
+func newDNSEnricher(params map[string]string) (Enricher, error) {
+	return dnsEnricher{}, nil
+}
+
+func (dnsEnricher) Name() string { return "dns" }
+
+func (dnsEnricher) Enrich(rec *Record) error {
+	addrs, err := net.LookupHost(rec.Hostname)
+	if err != nil || len(addrs) == 0 {
+		rec.Tag("dns_error", fmt.Sprintf("%v", err))
+		return nil
+	}
+	rec.Tag("ip", addrs[0])
+	return nil
+}
+
+// maxAgeAssertion fails a record whose chassis age (see
+// internal.CalculateAge) is at least maxYears. A record with no chassis
+// component, or one whose serial no registered internal.AgeDecoder
+// recognizes, passes - there's nothing to flag.
+type maxAgeAssertion struct {
+	maxYears int
+}
+
+func newMaxAgeAssertion(params map[string]string) (Assertion, error) {
+	raw, ok := params["max_years"]
+	if !ok {
+		return nil, fmt.Errorf("max-age assertion requires a max_years param")
+	}
+	maxYears, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max_years %q: %w", raw, err)
+	}
+	return maxAgeAssertion{maxYears: maxYears}, nil
+}
+
+func (maxAgeAssertion) Name() string { return "max-age" }
+
+func (a maxAgeAssertion) Check(rec Record) AssertionResult {
+	for _, c := range rec.Components {
+		if c.Role != "chassis" {
+			continue
+		}
+		age, ok := internal.CalculateAge(c.Serial)
+		if !ok {
+			return AssertionResult{Hostname: rec.Hostname, Stage: "max-age", Passed: true, Message: "chassis serial format not recognized"}
+		}
+		if age >= a.maxYears {
+			return AssertionResult{Hostname: rec.Hostname, Stage: "max-age", Passed: false, Message: fmt.Sprintf("chassis is %d years old (max %d)", age, a.maxYears)}
+		}
+		return AssertionResult{Hostname: rec.Hostname, Stage: "max-age", Passed: true, Message: fmt.Sprintf("chassis is %d years old", age)}
+	}
+	return AssertionResult{Hostname: rec.Hostname, Stage: "max-age", Passed: true, Message: "no chassis component"}
+}
+
+// exportRow is the per-device view csvExporter and jsonExporter both
+// render - one row per record. The CSV export joins its failed assertions
+// into a single column and leaves out Tags; the JSON export keeps both as
+// nested fields.
+type exportRow struct {
+	Hostname       string            `json:"hostname"`
+	ChassisSerial  string            `json:"chassis_serial,omitempty"`
+	ComponentCount int               `json:"component_count"`
+	InterfaceCount int               `json:"interface_count"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	FailedChecks   []string          `json:"failed_checks,omitempty"`
+}
+
+func buildExportRows(records []Record, results []AssertionResult) []exportRow {
+	failed := make(map[string][]string)
+	for _, r := range results {
+		if !r.Passed {
+			failed[r.Hostname] = append(failed[r.Hostname], fmt.Sprintf("%s: %s", r.Stage, r.Message))
+		}
+	}
+
+	rows := make([]exportRow, len(records))
+	for i, rec := range records {
+		row := exportRow{
+			Hostname:       rec.Hostname,
+			ComponentCount: len(rec.Components),
+			InterfaceCount: rec.InterfaceCount,
+			Tags:           rec.Tags,
+			FailedChecks:   failed[rec.Hostname],
+		}
+		for _, c := range rec.Components {
+			if c.Role == "chassis" {
+				row.ChassisSerial = c.Serial
+				break
+			}
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+var exportColumns = []output.Column{
+	{Header: "HOSTNAME", Value: func(r interface{}) string { return r.(exportRow).Hostname }},
+	{Header: "CHASSIS_SERIAL", Value: func(r interface{}) string { return r.(exportRow).ChassisSerial }},
+	{Header: "COMPONENT_COUNT", Value: func(r interface{}) string { return strconv.Itoa(r.(exportRow).ComponentCount) }},
+	{Header: "INTERFACE_COUNT", Value: func(r interface{}) string { return strconv.Itoa(r.(exportRow).InterfaceCount) }},
+	{Header: "FAILED_CHECKS", Value: func(r interface{}) string { return strings.Join(r.(exportRow).FailedChecks, "; ") }},
+}
+
+// csvExporter writes a flattened per-device CSV using the shared output
+// package, the same way internal.writeReportCSV does.
+type csvExporter struct {
+	path string
+}
+
+func newCSVExporter(params map[string]string) (Exporter, error) {
+	path, ok := params["path"]
+	if !ok {
+		return nil, fmt.Errorf("csv exporter requires a path param")
+	}
+	return csvExporter{path: path}, nil
+}
+
+func (csvExporter) Name() string { return "csv" }
+
+func (e csvExporter) Export(records []Record, results []AssertionResult) error {
+	file, err := os.Create(e.path)
+	if err != nil {
+		return fmt.Errorf("error creating CSV file: %w", err)
+	}
+	defer file.Close()
+
+	rows := buildExportRows(records, results)
+	anyRows := make([]interface{}, len(rows))
+	for i, row := range rows {
+		anyRows[i] = row
+	}
+
+	return output.Render(file, output.FormatCSV, rows, exportColumns, anyRows)
+}
+
+// jsonExporter writes the full per-device view, including tags and the list
+// of failed assertion messages, as indented JSON.
+type jsonExporter struct {
+	path string
+}
+
+func newJSONExporter(params map[string]string) (Exporter, error) {
+	path, ok := params["path"]
+	if !ok {
+		return nil, fmt.Errorf("json exporter requires a path param")
+	}
+	return jsonExporter{path: path}, nil
+}
+
+func (jsonExporter) Name() string { return "json" }
+
+func (e jsonExporter) Export(records []Record, results []AssertionResult) error {
+	rows := buildExportRows(records, results)
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling pipeline export: %w", err)
+	}
+	if err := os.WriteFile(e.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing pipeline export: %w", err)
+	}
+	return nil
+}
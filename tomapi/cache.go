@@ -1,11 +1,15 @@
 package tomapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Cache management response types
@@ -135,3 +139,150 @@ func (c *Client) GetCacheStats() (*CacheStatsResponse, error) {
 
 	return &result, nil
 }
+
+// WarmOptions controls how WarmCache spreads its requests across devices.
+type WarmOptions struct {
+	Concurrency    int           // max devices queried concurrently; default 5
+	PerDeviceDelay time.Duration // minimum gap between requests to the same device
+	TTL            int           // requested cache TTL in seconds; clamped to the server's max_ttl
+}
+
+// WarmResult is the outcome of warming a single (device, command) pair.
+type WarmResult struct {
+	Device  string
+	Command string
+	Error   error
+}
+
+// WarmCache proactively populates the server-side cache for every
+// (device, command) pair in devices x commands, so a subsequent bulk report
+// never pays a cold-cache penalty. Requests run with bounded concurrency
+// across devices, but are serialized per device (with PerDeviceDelay between
+// them) so warming doesn't itself trip a device's own command rate limit.
+func (c *Client) WarmCache(devices []string, commands []string, opts WarmOptions) ([]WarmResult, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 5
+	}
+
+	ttl := opts.TTL
+	if stats, err := c.GetCacheStats(); err == nil && stats.MaxTTL > 0 && (ttl <= 0 || ttl > stats.MaxTTL) {
+		ttl = stats.MaxTTL
+	}
+	var ttlPtr *int
+	if ttl > 0 {
+		ttlPtr = &ttl
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []WarmResult
+	)
+	sem := make(chan struct{}, concurrency)
+
+	for _, device := range devices {
+		wg.Add(1)
+		go func(dev string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			for i, command := range commands {
+				if i > 0 && opts.PerDeviceDelay > 0 {
+					time.Sleep(opts.PerDeviceDelay)
+				}
+
+				_, err := c.SendDeviceCommand(dev, command, true, true, true, ttlPtr, true)
+
+				mu.Lock()
+				results = append(results, WarmResult{Device: dev, Command: command, Error: err})
+				mu.Unlock()
+			}
+		}(device)
+	}
+
+	wg.Wait()
+	return results, nil
+}
+
+// WatchAndRefresh polls ListCacheKeys (optionally filtered by pattern, same
+// as ListCacheKeys's deviceName filter) every interval. It re-warms every
+// key on the first pass that lists it, and after that whenever 90% of the
+// server's default_ttl has passed since that key's last successful
+// refresh, so a long-running job never observes a key expiring mid-run.
+// It blocks until ctx is canceled, at which point it returns ctx.Err().
+func (c *Client) WatchAndRefresh(ctx context.Context, pattern string, interval time.Duration) error {
+	lastRefreshed := make(map[string]time.Time)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.refreshStaleKeys(pattern, lastRefreshed); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshStaleCache runs a single poll-and-refresh pass against keys
+// matching pattern (see ListCacheKeys) without any notion of prior age, so
+// every entry the server still remembers is treated as due and re-fetched.
+// It's the one-shot building block WatchAndRefresh loops on.
+func (c *Client) RefreshStaleCache(pattern string) error {
+	return c.refreshStaleKeys(pattern, make(map[string]time.Time))
+}
+
+// refreshStaleKeys is one poll-and-refresh pass of WatchAndRefresh.
+func (c *Client) refreshStaleKeys(pattern string, lastRefreshed map[string]time.Time) error {
+	stats, err := c.GetCacheStats()
+	if err != nil {
+		return fmt.Errorf("failed to get cache stats: %w", err)
+	}
+	if stats.DefaultTTL <= 0 {
+		return nil
+	}
+
+	keys, err := c.ListCacheKeys(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to list cache keys: %w", err)
+	}
+
+	jitter := time.Duration(stats.DefaultTTL) * time.Second / 10
+	staleAfter := time.Duration(stats.DefaultTTL)*time.Second - jitter
+
+	now := time.Now()
+	for _, key := range keys.Keys {
+		if last, seen := lastRefreshed[key]; seen && now.Sub(last) < staleAfter {
+			continue
+		}
+
+		device, command, ok := parseCacheKey(key)
+		if !ok {
+			continue
+		}
+
+		if _, err := c.SendDeviceCommand(device, command, true, true, true, &stats.DefaultTTL, true); err == nil {
+			lastRefreshed[key] = now
+		}
+	}
+
+	return nil
+}
+
+// parseCacheKey splits a server cache key of the form "device:command:hash"
+// (the same format cmd/cache.go's list output already assumes) into its
+// device and command parts.
+func parseCacheKey(key string) (device, command string, ok bool) {
+	parts := strings.SplitN(key, ":", 3)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -2,6 +2,7 @@ package tomapi
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -11,13 +12,43 @@ type Client struct {
 	BaseURL      string
 	AuthProvider AuthProvider
 	HTTPClient   *http.Client
+
+	// RetryPolicy, if set, makes every request issued through makeRequest/
+	// makeJSONRequest retry transient failures (see doWithRetry). Nil by
+	// default, which preserves the single-attempt behavior every existing
+	// caller already expects.
+	RetryPolicy *RetryPolicy
 }
 
-// NewClient creates a new Tom API client with the given auth provider
+// NewClient creates a new Tom API client with the given auth provider.
+// baseURL is normally an http(s) address, but a "unix:///path/to.sock" style
+// URL routes requests over that Unix domain socket instead - the socket path
+// is parsed out and the transport dials it directly, while the request URL's
+// host is rewritten to a fixed placeholder so makeRequest needs no special
+// casing. A "unix+https:///path/to.sock" URL does the same over a TLS
+// handshake, but with no way to carry a *tls.Config through a bare string,
+// verification runs against the placeholder host "unix", which no real
+// certificate names - use NewClientWithUnixSocketTLS instead, which takes an
+// explicit *tls.Config, for a socket that needs real verification.
 func NewClient(baseURL string, authProvider AuthProvider) *Client {
 	if authProvider == nil {
 		authProvider = &NoAuth{}
 	}
+
+	if socketPath, useTLS, ok := parseUnixSocketPath(baseURL); ok {
+		host := unixSocketBaseURL
+		var tlsConfig *tls.Config
+		if useTLS {
+			host = unixSocketHTTPSBaseURL
+			tlsConfig = &tls.Config{}
+		}
+		return &Client{
+			BaseURL:      host,
+			AuthProvider: authProvider,
+			HTTPClient:   &http.Client{Transport: unixSocketTransport(socketPath, tlsConfig)},
+		}
+	}
+
 	return &Client{
 		BaseURL:      baseURL,
 		AuthProvider: authProvider,
@@ -41,43 +72,52 @@ func NewClientWithToken(baseURL, token string) *Client {
 }
 
 func (c *Client) makeRequest(method, url string) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, nil)
-	if err != nil {
-		return nil, err
-	}
+	return c.doWithRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			return nil, err
+		}
 
-	if err := c.setAuthHeader(req); err != nil {
-		return nil, err
-	}
+		if err := c.setAuthHeader(req); err != nil {
+			return nil, err
+		}
 
-	return c.HTTPClient.Do(req)
+		return req, nil
+	})
 }
 
 // makeJSONRequest makes an HTTP request with a JSON body
 func (c *Client) makeJSONRequest(method, url string, body interface{}) (*http.Response, error) {
-	var reqBody io.Reader
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
-		reqBody = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, err
-	}
+	return c.doWithRetry(func() (*http.Request, error) {
+		var reqBody io.Reader
+		if jsonData != nil {
+			reqBody = bytes.NewBuffer(jsonData)
+		}
 
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
+		req, err := http.NewRequest(method, url, reqBody)
+		if err != nil {
+			return nil, err
+		}
 
-	if err := c.setAuthHeader(req); err != nil {
-		return nil, err
-	}
+		if jsonData != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		if err := c.setAuthHeader(req); err != nil {
+			return nil, err
+		}
 
-	return c.HTTPClient.Do(req)
+		return req, nil
+	})
 }
 
 func (c *Client) setAuthHeader(req *http.Request) error {
@@ -27,7 +27,7 @@ func (c *Client) SendDeviceCommand(deviceName, command string, wait bool, rawOut
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API returned status code: %d - %s", resp.StatusCode, string(body))
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -99,7 +99,7 @@ func (c *Client) SendDeviceCommandWithAuth(deviceName, command, username, passwo
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API returned status code: %d - %s", resp.StatusCode, string(body))
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -114,6 +114,29 @@ func (c *Client) SendDeviceCommandWithAuth(deviceName, command, username, passwo
 	return string(body), nil
 }
 
+// SendCommands sends a batch of commands to a device in a single job.
+func (c *Client) SendCommands(deviceName string, req SendCommandsRequest) (*JobResponse, error) {
+	apiURL := fmt.Sprintf("%s/api/device/%s/send_commands", c.BaseURL, deviceName)
+
+	resp, err := c.makeJSONRequest("POST", apiURL, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var job JobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &job, nil
+}
+
 // RecordJWT() sends a request to the /dev/record-jwt endpoint to record JWT token for a device
 func (c *Client) RecordJWT() error {
 	apiURL := fmt.Sprintf("%s/api/dev/record-jwt", c.BaseURL)
@@ -0,0 +1,15 @@
+package tomapi
+
+import "fmt"
+
+// StatusError is returned when the Tom API responds with a non-2xx status
+// code, so callers (e.g. the retry/backoff helpers in jobs.go) can inspect
+// the status code without parsing error strings.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned status code: %d - %s", e.StatusCode, e.Body)
+}
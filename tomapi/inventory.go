@@ -39,6 +39,75 @@ func (c *Client) ExportInventory(filter string) (map[string]DeviceConfig, error)
 	return inventory, nil
 }
 
+// ExportInventoryStream exports inventory the same way ExportInventory does,
+// but decodes the response incrementally with json.Decoder instead of
+// buffering the whole body, so callers can process multi-gigabyte exports
+// without holding the entire inventory in memory. The device channel is
+// closed when the export completes; the error channel receives at most one
+// error (nil on success) and is closed immediately after.
+func (c *Client) ExportInventoryStream(filter string) (<-chan Device, <-chan error) {
+	deviceChan := make(chan Device)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(deviceChan)
+		defer close(errChan)
+
+		apiURL := c.BaseURL + "/api/inventory/export"
+		if filter != "" {
+			apiURL += "?filter_name=" + url.QueryEscape(filter)
+		}
+
+		resp, err := c.makeRequest("GET", apiURL)
+		if err != nil {
+			errChan <- fmt.Errorf("failed to make request: %w", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			errChan <- &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+			return
+		}
+
+		dec := json.NewDecoder(resp.Body)
+
+		tok, err := dec.Token()
+		if err != nil {
+			errChan <- fmt.Errorf("failed to read response: %w", err)
+			return
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+			errChan <- fmt.Errorf("unexpected response format: expected a JSON object")
+			return
+		}
+
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				errChan <- fmt.Errorf("failed to read hostname: %w", err)
+				return
+			}
+			hostname, ok := keyTok.(string)
+			if !ok {
+				errChan <- fmt.Errorf("unexpected non-string key in response")
+				return
+			}
+
+			var cfg DeviceConfig
+			if err := dec.Decode(&cfg); err != nil {
+				errChan <- fmt.Errorf("failed to decode device %q: %w", hostname, err)
+				return
+			}
+
+			deviceChan <- Device{Hostname: hostname, DeviceConfig: cfg}
+		}
+	}()
+
+	return deviceChan, errChan
+}
+
 // ExportRawInventory exports raw inventory nodes
 func (c *Client) ExportRawInventory(filter string) ([]RawInventoryNode, error) {
 	apiURL := c.BaseURL + "/api/inventory/export/raw"
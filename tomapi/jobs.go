@@ -0,0 +1,207 @@
+package tomapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PollOptions configures the retry/backoff behavior of PollJob and
+// SendDeviceCommandWithRetry.
+type PollOptions struct {
+	Interval     time.Duration        // initial delay between attempts
+	MaxInterval  time.Duration        // backoff ceiling
+	RetryTimeout time.Duration        // overall wall-clock deadline
+	RetryOn      func(err error) bool // classifies which errors are worth retrying
+}
+
+const (
+	defaultPollInterval     = 2 * time.Second
+	defaultPollMaxInterval  = 30 * time.Second
+	defaultPollRetryTimeout = 2 * time.Minute
+)
+
+func (o *PollOptions) withDefaults() {
+	if o.Interval <= 0 {
+		o.Interval = defaultPollInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaultPollMaxInterval
+	}
+	if o.RetryTimeout <= 0 {
+		o.RetryTimeout = defaultPollRetryTimeout
+	}
+	if o.RetryOn == nil {
+		o.RetryOn = IsRetryableError
+	}
+}
+
+// IsRetryableError classifies errors as transient (worth retrying) or fatal.
+// 502/503/504 and network-level errors (connection reset, timeouts, DNS) are
+// retryable; everything else, including 4xx StatusErrors, is not.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset") || strings.Contains(msg, "EOF")
+}
+
+// GetJob fetches the current status of an asynchronous job.
+func (c *Client) GetJob(jobID string) (*JobResponse, error) {
+	apiURL := fmt.Sprintf("%s/api/jobs/%s", c.BaseURL, jobID)
+
+	resp, err := c.makeRequest("GET", apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var job JobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &job, nil
+}
+
+// PollJob polls GetJob until the job reaches a terminal status (anything
+// other than "queued"/"running"), the context is cancelled, or opts.RetryTimeout
+// elapses. The interval doubles after each attempt up to opts.MaxInterval. It
+// returns the final JobResponse along with the number of attempts made, so
+// callers can print progress like "Attempt #N".
+func PollJob(ctx context.Context, c *Client, jobID string, opts PollOptions) (*JobResponse, int, error) {
+	opts.withDefaults()
+
+	deadline := time.Now().Add(opts.RetryTimeout)
+	interval := opts.Interval
+	attempt := 0
+
+	for {
+		attempt++
+
+		job, err := c.GetJob(jobID)
+		if err == nil {
+			if job.Status != "queued" && job.Status != "running" {
+				return job, attempt, nil
+			}
+		} else if !opts.RetryOn(err) {
+			return nil, attempt, fmt.Errorf("job %s failed on attempt #%d: %w", jobID, attempt, err)
+		}
+
+		if time.Now().After(deadline) {
+			if err != nil {
+				return nil, attempt, fmt.Errorf("gave up polling job %s after %d attempts: %w", jobID, attempt, err)
+			}
+			return nil, attempt, fmt.Errorf("gave up polling job %s after %d attempts: still %q after %s", jobID, attempt, job.Status, opts.RetryTimeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, attempt, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+	}
+}
+
+// SendDeviceCommandWithRetry wraps SendDeviceCommand with the same
+// sleep/retry-until-timeout pattern as PollJob: on a transient error (network
+// issues, 502/503/504) it sleeps the configured backoff and retries the whole
+// request until opts.RetryTimeout elapses. It returns the number of attempts
+// made so callers can surface "Attempt #N" progress. A fatal error (one
+// opts.RetryOn rejects) is returned unwrapped on the first attempt, so
+// callers can tell "never retried" apart from "gave up after N attempts".
+func (c *Client) SendDeviceCommandWithRetry(deviceName, command string, wait, rawOutput bool, useCache bool, cacheTTL *int, cacheRefresh bool, opts PollOptions) (string, int, error) {
+	opts.withDefaults()
+
+	deadline := time.Now().Add(opts.RetryTimeout)
+	interval := opts.Interval
+	attempt := 0
+
+	for {
+		attempt++
+
+		result, err := c.SendDeviceCommand(deviceName, command, wait, rawOutput, useCache, cacheTTL, cacheRefresh)
+		if err == nil {
+			return result, attempt, nil
+		}
+
+		if !opts.RetryOn(err) || time.Now().After(deadline) {
+			if attempt > 1 {
+				return "", attempt, fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+			}
+			return "", attempt, err
+		}
+
+		time.Sleep(interval)
+		interval *= 2
+		if interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+	}
+}
+
+// SendDeviceCommandWithAuthAndRetry wraps SendDeviceCommandWithAuth with the
+// same sleep/retry-until-timeout pattern as SendDeviceCommandWithRetry, for
+// callers overriding device credentials.
+func (c *Client) SendDeviceCommandWithAuthAndRetry(deviceName, command, username, password string, wait, rawOutput bool, timeout int, useCache bool, cacheTTL *int, cacheRefresh bool, opts PollOptions) (string, int, error) {
+	opts.withDefaults()
+
+	deadline := time.Now().Add(opts.RetryTimeout)
+	interval := opts.Interval
+	attempt := 0
+
+	for {
+		attempt++
+
+		result, err := c.SendDeviceCommandWithAuth(deviceName, command, username, password, wait, rawOutput, timeout, useCache, cacheTTL, cacheRefresh)
+		if err == nil {
+			return result, attempt, nil
+		}
+
+		if !opts.RetryOn(err) || time.Now().After(deadline) {
+			if attempt > 1 {
+				return "", attempt, fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+			}
+			return "", attempt, err
+		}
+
+		time.Sleep(interval)
+		interval *= 2
+		if interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+	}
+}
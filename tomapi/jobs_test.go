@@ -0,0 +1,91 @@
+package tomapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendDeviceCommandWithRetryFlakes(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode("ok")
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, nil)
+
+	result, gotAttempts, err := client.SendDeviceCommandWithRetry("router1", "show version", true, true, false, nil, false, PollOptions{
+		Interval:     time.Millisecond,
+		MaxInterval:  5 * time.Millisecond,
+		RetryTimeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("expected eventual success, got error: %v", err)
+	}
+	if result != "ok" {
+		t.Errorf("expected result %q, got %q", "ok", result)
+	}
+	if gotAttempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", gotAttempts)
+	}
+}
+
+func TestSendDeviceCommandWithRetryGivesUpOnFatalError(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, nil)
+
+	_, gotAttempts, err := client.SendDeviceCommandWithRetry("router1", "show version", true, true, false, nil, false, PollOptions{
+		Interval:     time.Millisecond,
+		MaxInterval:  5 * time.Millisecond,
+		RetryTimeout: time.Second,
+	})
+	if err == nil {
+		t.Fatal("expected error for non-retryable status code, got nil")
+	}
+	if gotAttempts != 1 {
+		t.Errorf("expected exactly 1 attempt for a fatal error, got %d", gotAttempts)
+	}
+	if strings.Contains(err.Error(), "gave up after") {
+		t.Errorf("fatal error on first attempt should be unwrapped, got: %v", err)
+	}
+}
+
+func TestSendDeviceCommandWithRetryGivesUpAfterTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, nil)
+
+	_, gotAttempts, err := client.SendDeviceCommandWithRetry("router1", "show version", true, true, false, nil, false, PollOptions{
+		Interval:     time.Millisecond,
+		MaxInterval:  5 * time.Millisecond,
+		RetryTimeout: 20 * time.Millisecond,
+	})
+	if err == nil {
+		t.Fatal("expected error after retry timeout elapses, got nil")
+	}
+	if gotAttempts <= 1 {
+		t.Errorf("expected more than 1 attempt before giving up, got %d", gotAttempts)
+	}
+	if !strings.Contains(err.Error(), "gave up after") {
+		t.Errorf("expected exhausted-retries error to mention giving up, got: %v", err)
+	}
+}
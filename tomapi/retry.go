@@ -0,0 +1,182 @@
+package tomapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures transparent retry/backoff for every request made
+// through Client.makeRequest/makeJSONRequest. Unlike PollOptions (which
+// wraps individual endpoint calls such as SendDeviceCommandWithRetry), a
+// RetryPolicy lives on the Client itself, so every endpoint built on
+// makeRequest/makeJSONRequest - including the cache endpoints and anything
+// added later - gets retry coverage for free, without a dedicated
+// *WithRetry twin. A nil Client.RetryPolicy (the default) makes exactly one
+// attempt, identical to the client's behavior before RetryPolicy existed.
+type RetryPolicy struct {
+	MaxAttempts       int                  // attempts before giving up; default 5
+	PerAttemptTimeout time.Duration        // timeout applied to each individual attempt; 0 disables
+	RetryTimeout      time.Duration        // overall wall-clock deadline across all attempts; default 1m
+	Backoff           time.Duration        // initial sleep between attempts; default 1s
+	MaxBackoff        time.Duration        // backoff ceiling; default 30s
+	Jitter            time.Duration        // random jitter added on top of each sleep
+	RetryOn           func(err error) bool // classifies which errors are worth retrying; default IsRetryableError
+}
+
+const (
+	defaultRetryMaxAttempts = 5
+	defaultRetryTimeout     = time.Minute
+	defaultRetryBackoff     = time.Second
+	defaultRetryMaxBackoff  = 30 * time.Second
+)
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if p.RetryTimeout <= 0 {
+		p.RetryTimeout = defaultRetryTimeout
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = defaultRetryBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if p.RetryOn == nil {
+		p.RetryOn = IsRetryableError
+	}
+	return p
+}
+
+// RetryTimeoutError is returned by doWithRetry when a request under a
+// RetryPolicy still hasn't succeeded once its attempts or RetryTimeout are
+// exhausted, so callers (e.g. cmd/cache.go's startup-orchestration use case)
+// can tell "gave up waiting" apart from a plain, non-retryable failure and
+// exit with a distinct code.
+type RetryTimeoutError struct {
+	Attempts int
+	Last     error
+}
+
+func (e *RetryTimeoutError) Error() string {
+	return fmt.Sprintf("gave up after %d attempts: %v", e.Attempts, e.Last)
+}
+
+func (e *RetryTimeoutError) Unwrap() error {
+	return e.Last
+}
+
+// retryableStatusCode reports whether an HTTP status code is worth
+// retrying, mirroring IsRetryableError's StatusError branch.
+func retryableStatusCode(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// doWithRetry builds and executes a request via buildReq, retrying on
+// transient failures per c.RetryPolicy. buildReq is invoked fresh on every
+// attempt, since a request body, once consumed, can't be resent. With a nil
+// RetryPolicy it makes exactly one attempt and returns immediately,
+// preserving makeRequest/makeJSONRequest's pre-RetryPolicy behavior.
+func (c *Client) doWithRetry(buildReq func() (*http.Request, error)) (*http.Response, error) {
+	if c.RetryPolicy == nil {
+		req, err := buildReq()
+		if err != nil {
+			return nil, err
+		}
+		return c.HTTPClient.Do(req)
+	}
+
+	policy := c.RetryPolicy.withDefaults()
+	deadline := time.Now().Add(policy.RetryTimeout)
+	backoff := policy.Backoff
+
+	var attempt int
+	for {
+		attempt++
+
+		resp, err := c.doOneAttempt(buildReq, policy.PerAttemptTimeout)
+		if err == nil {
+			return resp, nil
+		}
+
+		retryable := policy.RetryOn(err)
+		exhausted := attempt >= policy.MaxAttempts || time.Now().After(deadline)
+
+		if !retryable {
+			return nil, err
+		}
+		if exhausted {
+			return nil, &RetryTimeoutError{Attempts: attempt, Last: err}
+		}
+
+		sleep := backoff
+		if policy.Jitter > 0 {
+			sleep += time.Duration(rand.Int63n(int64(policy.Jitter)))
+		}
+		time.Sleep(sleep)
+
+		backoff *= 2
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+// doOneAttempt runs a single request/response round trip, applying
+// perAttemptTimeout if set and turning a retryable status code into a
+// StatusError so doWithRetry's RetryOn check can see it.
+func (c *Client) doOneAttempt(buildReq func() (*http.Request, error), perAttemptTimeout time.Duration) (*http.Response, error) {
+	req, err := buildReq()
+	if err != nil {
+		return nil, err
+	}
+
+	cancel := func() {}
+	if perAttemptTimeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), perAttemptTimeout)
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if retryableStatusCode(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		cancel()
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	// The timeout must outlive this function - the caller hasn't read the
+	// body yet - so defer cancel() to Close() instead of firing it here,
+	// which would abort every successful read with "context canceled".
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnCloseBody ties a per-attempt context's cancel func to the response
+// body's lifetime, so PerAttemptTimeout bounds the whole request (including
+// the body read) instead of just the round trip up to doOneAttempt's return.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,40 @@
+package tomapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestPerAttemptTimeoutDoesNotCancelBodyRead guards against the timeout
+// context being canceled before the caller reads the response body: that
+// used to fail every successful read with "context canceled" the instant
+// doOneAttempt returned.
+func TestPerAttemptTimeoutDoesNotCancelBodyRead(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, nil)
+	client.RetryPolicy = &RetryPolicy{PerAttemptTimeout: time.Second}
+
+	resp, err := client.doOneAttempt(func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, srv.URL, nil)
+	}, client.RetryPolicy.PerAttemptTimeout)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("expected body read to succeed, got: %v", err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("expected body %q, got %q", "ok", string(body))
+	}
+}
@@ -0,0 +1,87 @@
+package tomapi
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// unixSocketBaseURL and unixSocketHTTPSBaseURL are placeholder hosts used
+// when requests are routed over a Unix domain socket rather than TCP. The
+// host is never actually resolved - the transport's DialContext below always
+// dials the socket path instead, and the scheme only tells the transport
+// whether to perform a TLS handshake over that connection.
+const (
+	unixSocketBaseURL      = "http://unix"
+	unixSocketHTTPSBaseURL = "https://unix"
+)
+
+// unixSocketURLScheme and unixSocketTLSURLScheme mark a Client base URL as a
+// Unix domain socket path, e.g. "unix:///var/run/tom.sock" or
+// "unix+https:///var/run/tom.sock" for a socket speaking TLS, rather than an
+// http(s) address.
+const (
+	unixSocketURLScheme    = "unix://"
+	unixSocketTLSURLScheme = "unix+https://"
+)
+
+// parseUnixSocketPath extracts the socket path and TLS-ness from a "unix://"
+// or "unix+https://" style base URL. ok is false for any other scheme,
+// leaving baseURL to be handled as a normal http(s) URL.
+func parseUnixSocketPath(baseURL string) (path string, useTLS bool, ok bool) {
+	switch {
+	case strings.HasPrefix(baseURL, unixSocketTLSURLScheme):
+		return strings.TrimPrefix(baseURL, unixSocketTLSURLScheme), true, true
+	case strings.HasPrefix(baseURL, unixSocketURLScheme):
+		return strings.TrimPrefix(baseURL, unixSocketURLScheme), false, true
+	default:
+		return "", false, false
+	}
+}
+
+// unixSocketTransport returns an http.Transport that dials socketPath for
+// every request, ignoring whatever host and network the request's URL names.
+// The same DialContext serves both plain and TLS-on-unix clients: for a
+// "https://unix" base URL, Transport performs the TLS handshake itself over
+// the raw conn this returns, using tlsConfig (nil for a plaintext socket).
+// tlsConfig should set ServerName explicitly - the request's placeholder host
+// is always the literal string "unix", which no real certificate is issued
+// for, so leaving ServerName unset makes verification fail against anything
+// but a server configured to skip it.
+func unixSocketTransport(socketPath string, tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		},
+		TLSClientConfig: tlsConfig,
+	}
+}
+
+// NewClientWithUnixSocket creates a Tom API client that connects over a Unix
+// domain socket at socketPath instead of TCP. This is useful when the Tom API
+// server is only reachable through a local socket (e.g. an SSH tunnel or a
+// sidecar proxy) rather than a TCP listener.
+func NewClientWithUnixSocket(socketPath string, authProvider AuthProvider) *Client {
+	return NewClient(unixSocketURLScheme+socketPath, authProvider)
+}
+
+// NewClientWithUnixSocketTLS is NewClientWithUnixSocket for a socket whose far
+// end speaks TLS rather than plaintext HTTP. tlsConfig is passed to the
+// transport as-is, so callers must set ServerName (and RootCAs, if the far
+// end doesn't present a certificate from the system trust store) to whatever
+// the far end's certificate actually names; the request itself always
+// addresses the fixed placeholder host "unix", which cannot verify against a
+// real certificate on its own.
+func NewClientWithUnixSocketTLS(socketPath string, tlsConfig *tls.Config, authProvider AuthProvider) *Client {
+	if authProvider == nil {
+		authProvider = &NoAuth{}
+	}
+	return &Client{
+		BaseURL:      unixSocketHTTPSBaseURL,
+		AuthProvider: authProvider,
+		HTTPClient:   &http.Client{Transport: unixSocketTransport(socketPath, tlsConfig)},
+	}
+}
@@ -23,6 +23,13 @@ type DeviceConfig struct {
 	CredentialID   string                 `json:"credential_id"`
 }
 
+// Device pairs a hostname with its DeviceConfig, used by ExportInventoryStream
+// so callers can process inventory records one at a time.
+type Device struct {
+	Hostname string `json:"hostname"`
+	DeviceConfig
+}
+
 // RawInventoryNode represents a raw inventory node (SolarWinds format)
 type RawInventoryNode struct {
 	NodeID      int    `json:"NodeID"`